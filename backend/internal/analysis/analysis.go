@@ -0,0 +1,284 @@
+// Package analysis runs the engine over the turns of a finished m,n,k game
+// and marks the turns that threw away a win or lost a game that was not
+// lost.
+package analysis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"webgames/internal/engine"
+	"webgames/internal/mnkgame"
+	"webgames/internal/threats"
+)
+
+// Flag marks a turn worth a second look.
+type Flag int
+
+const (
+	FlagNone Flag = iota
+	// FlagMistake gives away much of the position without deciding it.
+	FlagMistake
+	// FlagBlunder turns a game that was not lost into a lost one.
+	FlagBlunder
+	// FlagMissedWin lets a won game slip.
+	FlagMissedWin
+)
+
+var flagName = map[Flag]string{
+	FlagNone:      "",
+	FlagMistake:   "Mistake",
+	FlagBlunder:   "Blunder",
+	FlagMissedWin: "Missed win",
+}
+
+func (f Flag) String() string {
+	return flagName[f]
+}
+
+// Eval is the analysis of one turn of History.
+type Eval struct {
+	Player mnkgame.Player
+	Stones []mnkgame.Position
+	// Best is the engine's move before the turn. Before and After score the
+	// position before and after the turn for the mover, as engine scores.
+	Best   engine.Move
+	Before int
+	After  int
+	Flag   Flag
+	// Graph is After for X squeezed into -1..1, 1 being a win of X.
+	Graph float64
+}
+
+// positions returns the game before every turn of its history, and after
+// the last one.
+func positions(g *mnkgame.Game) ([]*mnkgame.Game, error) {
+	c := g.Clone()
+	out := make([]*mnkgame.Game, len(g.History)+1)
+	out[len(g.History)] = c.Clone()
+
+	for i := len(g.History) - 1; i >= 0; i-- {
+		for range g.History[i].Stones {
+			if err := mnkgame.UndoTurn(c); err != nil {
+				return nil, err
+			}
+		}
+		out[i] = c.Clone()
+	}
+
+	return out, nil
+}
+
+// value returns the engine's move and the score of the position for the
+// side to move, a forced win found by the threat-space search first.
+func value(g *mnkgame.Game) (engine.Move, int) {
+	if win, ok := threats.FindVCT(g); ok {
+		return engine.Move{Position: win.Moves[0]}, engine.WinIn(len(win.Moves))
+	}
+	if move, score, ok := engine.Search(g); ok {
+		return move, score
+	}
+	return engine.Move{}, engine.Score(g, g.Turn)
+}
+
+// Analyse evaluates the turns of g one after another, calling found with
+// each evaluation as it is ready. It stops early if ctx is cancelled.
+//
+// In two-sided games the position after a turn is scored by the search the
+// other side gets before its turn, so its forced wins count, and a win kept
+// through the other side's reply counts as kept. An evaluation is therefore
+// ready two turns after its own.
+func Analyse(ctx context.Context, g *mnkgame.Game, found func(Eval)) error {
+	games, err := positions(g)
+	if err != nil {
+		return err
+	}
+
+	turns := g.History
+	evals := make([]Eval, len(turns))
+	ready := 0
+
+	finish := func(j int) {
+		e := &evals[j]
+		after := games[j+1]
+
+		switch {
+		case after.Status != mnkgame.StatusTurn || g.Players != 2 || j+1 == len(turns):
+			e.After = engine.Score(after, e.Player)
+		default:
+			e.After = -evals[j+1].Before
+			if j+2 < len(turns) && engine.IsWin(e.Before) && engine.IsWin(evals[j+2].Before) {
+				e.After = evals[j+2].Before
+			}
+		}
+
+		e.Flag = flagOf(e.Before, e.After, g.WinRow)
+		e.Graph = graph(e.After, e.Player, g.WinRow)
+		found(*e)
+	}
+
+	for i, turn := range turns {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		evals[i] = Eval{Player: turn.Player, Stones: turn.Stones}
+		evals[i].Best, evals[i].Before = value(games[i])
+
+		for ; ready+2 <= i; ready++ {
+			finish(ready)
+		}
+	}
+	for ; ready < len(turns); ready++ {
+		finish(ready)
+	}
+
+	return nil
+}
+
+func flagOf(before, after, winRow int) Flag {
+	switch {
+	case engine.IsWin(before) && !engine.IsWin(after):
+		return FlagMissedWin
+	case !engine.IsLoss(before) && engine.IsLoss(after):
+		return FlagBlunder
+	case !engine.IsWin(before) && !engine.IsLoss(after) && before-after >= mistakeDrop(winRow):
+		return FlagMistake
+	}
+	return FlagNone
+}
+
+// mistakeDrop is what a window one stone short of a line is worth to the
+// engine, capped where the shift would overflow on long lines.
+func mistakeDrop(winRow int) int {
+	return 1 << min(3*(winRow-1), 60)
+}
+
+func graph(score int, mover mnkgame.Player, winRow int) float64 {
+	if mover != mnkgame.PlayerX {
+		score = -score
+	}
+
+	switch {
+	case engine.IsWin(score):
+		return 1
+	case engine.IsLoss(score):
+		return -1
+	}
+
+	v := float64(score)
+	scale := float64(mistakeDrop(winRow))
+	if v < 0 {
+		return v / (scale - v)
+	}
+	return v / (scale + v)
+}
+
+// Report collects the analysis of a finished game as it comes in.
+type Report struct {
+	Game *mnkgame.Game
+
+	mu    sync.Mutex
+	evals []Eval
+	done  bool
+
+	// watchers counts the callers of Start that have not released the
+	// report; the analysis is cancelled when the last one leaves early.
+	watchers int
+	cancel   context.CancelFunc
+}
+
+// Evals returns the turns analysed so far and whether all are done.
+func (r *Report) Evals() ([]Eval, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.evals[:len(r.evals):len(r.evals)], r.done
+}
+
+const (
+	// MaxWorkers bounds the analyses running at once, the others wait for
+	// a free worker.
+	MaxWorkers = 2
+	// KeepFinished is how long a finished report is kept for the next
+	// request before it is analysed again.
+	KeepFinished = 10 * time.Minute
+)
+
+var (
+	mu      sync.Mutex
+	reports = map[mnkgame.GameID]*Report{}
+	workers = make(chan struct{}, MaxWorkers)
+)
+
+// Start returns the report of a finished game, starting its analysis in
+// the background the first time. updated is called whenever a turn is
+// analysed and once the analysis ends. The caller has to call release when
+// it no longer watches the report.
+func Start(g *mnkgame.Game, updated func()) (r *Report, release func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	r, ok := reports[g.ID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		r = &Report{Game: g.Clone(), cancel: cancel}
+		reports[g.ID] = r
+		go r.run(ctx, updated)
+	}
+	r.watchers++
+
+	return r, func() { r.release() }
+}
+
+// run analyses the game once a worker is free.
+func (r *Report) run(ctx context.Context, updated func()) {
+	select {
+	case workers <- struct{}{}:
+		defer func() { <-workers }()
+	case <-ctx.Done():
+		return
+	}
+
+	err := Analyse(ctx, r.Game, func(e Eval) {
+		r.mu.Lock()
+		r.evals = append(r.evals, e)
+		r.mu.Unlock()
+		updated()
+	})
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.done = true
+	r.mu.Unlock()
+	updated()
+
+	time.AfterFunc(KeepFinished, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		r.forget()
+	})
+}
+
+// release drops a watcher, cancelling an unfinished analysis nobody
+// watches any more.
+func (r *Report) release() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	r.watchers--
+	if _, done := r.Evals(); !done && r.watchers == 0 {
+		r.cancel()
+		r.forget()
+	}
+}
+
+// forget removes r from the reports; mu has to be held.
+func (r *Report) forget() {
+	if reports[r.Game.ID] == r {
+		delete(reports, r.Game.ID)
+	}
+}
@@ -0,0 +1,129 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"webgames/internal/mnkgame"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func analyse(t *testing.T, g *mnkgame.Game) []Eval {
+	t.Helper()
+
+	var evals []Eval
+	require.Nil(t, Analyse(context.Background(), g, func(e Eval) {
+		evals = append(evals, e)
+	}))
+	require.Len(t, evals, len(g.History))
+	return evals
+}
+
+func play(t *testing.T, g *mnkgame.Game, moves ...mnkgame.Position) {
+	t.Helper()
+	for _, pos := range moves {
+		require.Nil(t, mnkgame.MakeTurn(g, pos))
+	}
+}
+
+func TestAnalyseFlagsBlunderAndMissedWin(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	play(t, g,
+		mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 1, Y: 1},
+		mnkgame.Position{X: 0, Y: 1},
+		// O does not block the column
+		mnkgame.Position{X: 2, Y: 2},
+		// X does not complete it
+		mnkgame.Position{X: 2, Y: 1},
+		mnkgame.Position{X: 0, Y: 2},
+	)
+
+	evals := analyse(t, g)
+	assert.Equal(t, FlagBlunder, evals[3].Flag)
+	assert.Equal(t, FlagMissedWin, evals[4].Flag)
+	assert.Equal(t, mnkgame.Position{X: 0, Y: 2}, evals[4].Best.Position)
+	assert.Equal(t, FlagNone, evals[0].Flag)
+}
+
+func TestAnalyseFinishedGame(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	play(t, g,
+		mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 1, Y: 0},
+		mnkgame.Position{X: 0, Y: 1}, mnkgame.Position{X: 1, Y: 1},
+		mnkgame.Position{X: 0, Y: 2},
+	)
+	require.Equal(t, mnkgame.StatusWin, g.Status)
+
+	evals := analyse(t, g)
+	last := evals[len(evals)-1]
+	assert.Equal(t, float64(1), last.Graph)
+	assert.Equal(t, FlagNone, last.Flag)
+
+	// answering a corner opening next to it loses, the centre holds
+	assert.Equal(t, FlagBlunder, evals[1].Flag)
+	assert.Equal(t, mnkgame.Position{X: 1, Y: 1}, evals[1].Best.Position)
+	assert.Equal(t, FlagNone, evals[3].Flag)
+}
+
+func TestAnalyseConnectTurns(t *testing.T) {
+	g := mnkgame.NewConnectGame(9, 9, 5, 2, 1)
+	play(t, g,
+		mnkgame.Position{X: 4, Y: 4},
+		mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 0, Y: 1},
+		mnkgame.Position{X: 4, Y: 5}, mnkgame.Position{X: 4, Y: 6},
+	)
+
+	evals := analyse(t, g)
+	assert.Equal(t, []mnkgame.Position{{X: 0, Y: 0}, {X: 0, Y: 1}}, evals[1].Stones)
+	assert.Equal(t, mnkgame.PlayerX, evals[2].Player)
+}
+
+func TestStartReportsProgress(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	play(t, g,
+		mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 1, Y: 0},
+		mnkgame.Position{X: 0, Y: 1}, mnkgame.Position{X: 1, Y: 1},
+		mnkgame.Position{X: 0, Y: 2},
+	)
+
+	updates := make(chan struct{}, 10)
+	r, release := Start(g, func() { updates <- struct{}{} })
+	defer release()
+	again, releaseAgain := Start(g, func() {})
+	assert.Same(t, r, again)
+	releaseAgain()
+
+	for {
+		<-updates
+		if evals, done := r.Evals(); done {
+			assert.Len(t, evals, 5)
+			break
+		}
+	}
+}
+
+func TestReleaseCancelsUnwatchedAnalysis(t *testing.T) {
+	g := mnkgame.NewGame(15, 15, 5)
+	g.ID = "released"
+	play(t, g, mnkgame.Position{X: 7, Y: 7}, mnkgame.Position{X: 8, Y: 8})
+
+	r, release := Start(g, func() {})
+	release()
+
+	mu.Lock()
+	_, ok := reports[g.ID]
+	mu.Unlock()
+	assert.False(t, ok, "an unfinished analysis nobody watches is dropped")
+
+	again, release := Start(g, func() {})
+	defer release()
+	assert.NotSame(t, r, again)
+}
+
+func TestMistakeDropOnLongLines(t *testing.T) {
+	assert.Equal(t, 1<<12, mistakeDrop(5))
+	assert.Positive(t, mistakeDrop(22))
+	assert.Positive(t, mistakeDrop(50))
+}
@@ -0,0 +1,303 @@
+// Package bitboard packs two-mark m,n,k boards into bit sets for engines.
+// Rows are laid out one after another with an always empty guard column
+// after each, so shifting a bit set by 1, stride, stride+1 or stride-1
+// moves every stone one step along a line without wrapping into the next
+// row, and a run of k stones is found with k-1 shifts and ands.
+package bitboard
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"webgames/internal/mnkgame"
+)
+
+// MaxSize is the largest width and height a Board can hold.
+const MaxSize = 50
+
+const words = (MaxSize*(MaxSize+1) + 63) / 64
+
+// Bits is a set of cells indexed by Board.Index.
+type Bits [words]uint64
+
+func (b *Bits) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b *Bits) clear(i int) {
+	b[i/64] &^= 1 << (i % 64)
+}
+
+func (b *Bits) has(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+// Count returns the number of cells in the set.
+func (b *Bits) Count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// shiftDown sets dst to the first n words of src moved s bits towards bit 0,
+// so bit i of dst is bit i+s of src.
+func shiftDown(dst, src *Bits, s, n int) {
+	whole, part := s/64, uint(s%64)
+	for i := range n {
+		var w uint64
+		if j := i + whole; j < n {
+			w = src[j] >> part
+			if part != 0 && j+1 < n {
+				w |= src[j+1] << (64 - part)
+			}
+		}
+		dst[i] = w
+	}
+}
+
+// shiftUp sets dst to the first n words of src moved s bits away from bit
+// 0, so bit i+s of dst is bit i of src.
+func shiftUp(dst, src *Bits, s, n int) {
+	whole, part := s/64, uint(s%64)
+	for i := n - 1; i >= 0; i-- {
+		var w uint64
+		if j := i - whole; j >= 0 {
+			w = src[j] << part
+			if part != 0 && j > 0 {
+				w |= src[j-1] >> (64 - part)
+			}
+		}
+		dst[i] = w
+	}
+}
+
+// Board holds the X and O stones of a flat square-grid board.
+type Board struct {
+	Width  int
+	Height int
+	WinRow int
+	// Exact counts only lines of exactly WinRow stones, as in Order and
+	// Chaos.
+	Exact bool
+	Rules mnkgame.Rules
+	Turn  mnkgame.Player
+	// X and O hold the cells of each mark.
+	X Bits
+	O Bits
+
+	stride int
+	words  int
+	full   Bits
+}
+
+// New creates an empty board with X to move.
+func New(width, height, winRow int) (*Board, error) {
+	if width < 1 || height < 1 || width > MaxSize || height > MaxSize {
+		return nil, fmt.Errorf("board size %dx%d is not within 1x1 and %dx%d", width, height, MaxSize, MaxSize)
+	}
+	if winRow < 1 {
+		return nil, fmt.Errorf("invalid win row %d", winRow)
+	}
+
+	b := &Board{
+		Width:  width,
+		Height: height,
+		WinRow: winRow,
+		Turn:   mnkgame.PlayerX,
+		stride: width + 1,
+	}
+	b.words = (height*b.stride + 63) / 64
+	for y := range height {
+		for x := range width {
+			b.full.set(b.Index(x, y))
+		}
+	}
+
+	return b, nil
+}
+
+// FromGame packs the board of g. Only two-mark games on a flat square grid
+// whose lines are decided by the stones alone can be packed.
+func FromGame(g *mnkgame.Game) (*Board, error) {
+	switch {
+	case g.Infinite:
+		return nil, errors.New("infinite boards cannot be packed")
+	case g.Geometry != mnkgame.GeometrySquare || g.Layers() > 1:
+		return nil, fmt.Errorf("%s boards cannot be packed", g.Geometry)
+	case g.Topology != mnkgame.TopologyFlat:
+		return nil, fmt.Errorf("%s boards cannot be packed", g.Topology)
+	case g.Players > 2:
+		return nil, errors.New("only two-sided games can be packed")
+	case g.Variant != "" || len(g.Blocked) > 0:
+		return nil, errors.New("scripted variants cannot be packed")
+	case g.Rules != mnkgame.RulesStandard && g.Rules != mnkgame.RulesMisere && g.Rules != mnkgame.RulesOrderChaos:
+		return nil, fmt.Errorf("%s games cannot be packed", g.Rules)
+	}
+
+	b, err := New(g.Width, g.Height, g.WinRow)
+	if err != nil {
+		return nil, err
+	}
+	b.Exact = g.Rules.Exact()
+	b.Rules = g.Rules
+	b.Turn = g.Turn
+
+	for pos, cell := range g.Board {
+		if err := b.Set(pos.X, pos.Y, cell); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// Game unpacks the board into a new game with the side to move of the
+// board. It is not stored and has no seats; ending it is left to the caller.
+func (b *Board) Game() *mnkgame.Game {
+	g := mnkgame.NewGame(b.Width, b.Height, b.WinRow)
+	g.Rules = b.Rules
+	g.Turn = b.Turn
+
+	for y := range b.Height {
+		for x := range b.Width {
+			if cell := b.Cell(x, y); cell != mnkgame.CellEmpty {
+				g.Board[mnkgame.Position{X: x, Y: y}] = cell
+			}
+		}
+	}
+	g.Hash = g.Board.Hash()
+
+	return g
+}
+
+// Index returns the bit of the cell at x, y.
+func (b *Board) Index(x, y int) int {
+	return y*b.stride + x
+}
+
+func (b *Board) inBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.Width && y < b.Height
+}
+
+// Cell returns the mark at x, y.
+func (b *Board) Cell(x, y int) mnkgame.Cell {
+	i := b.Index(x, y)
+	switch {
+	case b.X.has(i):
+		return mnkgame.CellX
+	case b.O.has(i):
+		return mnkgame.CellO
+	}
+	return mnkgame.CellEmpty
+}
+
+func (b *Board) marks(cell mnkgame.Cell) *Bits {
+	if cell == mnkgame.CellO {
+		return &b.O
+	}
+	return &b.X
+}
+
+// Set places cell at x, y, or clears the cell for CellEmpty. The side to
+// move is left alone.
+func (b *Board) Set(x, y int, cell mnkgame.Cell) error {
+	if !b.inBounds(x, y) {
+		return fmt.Errorf("cell %d,%d is out of bounds", x, y)
+	}
+	if cell != mnkgame.CellEmpty && cell != mnkgame.CellX && cell != mnkgame.CellO {
+		return fmt.Errorf("cannot pack %s", cell)
+	}
+
+	i := b.Index(x, y)
+	b.X.clear(i)
+	b.O.clear(i)
+	if cell != mnkgame.CellEmpty {
+		b.marks(cell).set(i)
+	}
+
+	return nil
+}
+
+// Play places the mark of the side to move on the empty cell at x, y and
+// passes the turn.
+func (b *Board) Play(x, y int) {
+	b.marks(b.Turn.Cell()).set(b.Index(x, y))
+	b.Turn = 3 - b.Turn
+}
+
+// Undo takes back the stone at x, y played by the other side.
+func (b *Board) Undo(x, y int) {
+	b.Turn = 3 - b.Turn
+	b.marks(b.Turn.Cell()).clear(b.Index(x, y))
+}
+
+// Empty returns the cells holding no stone.
+func (b *Board) Empty() Bits {
+	var empty Bits
+	for i := range b.words {
+		empty[i] = b.full[i] &^ (b.X[i] | b.O[i])
+	}
+	return empty
+}
+
+// Full reports whether every cell holds a stone.
+func (b *Board) Full() bool {
+	for i := range b.words {
+		if b.full[i]&^(b.X[i]|b.O[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// HasLine reports whether the stones of cell form a line of WinRow.
+func (b *Board) HasLine(cell mnkgame.Cell) bool {
+	stones := b.marks(cell)
+	for _, s := range []int{1, b.stride, b.stride + 1, b.stride - 1} {
+		if b.lineStarts(stones, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineStarts reports whether some stone begins a run of WinRow along the
+// direction with shift s.
+func (b *Board) lineStarts(stones *Bits, s int) bool {
+	n := b.words
+
+	// run has bit i set while i, i+s, ..., i+(done-1)s are all stones,
+	// doubling done while it can to save shifts
+	run, shifted := *stones, Bits{}
+	done := 1
+	for done < b.WinRow {
+		step := min(done, b.WinRow-done)
+		shiftDown(&shifted, &run, step*s, n)
+		for i := range n {
+			run[i] &= shifted[i]
+		}
+		done += step
+	}
+
+	if b.Exact {
+		// drop runs with a stone right before or right after them
+		shiftUp(&shifted, stones, s, n)
+		for i := range n {
+			run[i] &^= shifted[i]
+		}
+		shiftDown(&shifted, stones, b.WinRow*s, n)
+		for i := range n {
+			run[i] &^= shifted[i]
+		}
+	}
+
+	for i := range n {
+		if run[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
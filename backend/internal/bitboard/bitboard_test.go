@@ -0,0 +1,244 @@
+package bitboard
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"webgames/internal/mnkgame"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hasLine scans every cell and direction for a run of winRow equal marks.
+func hasLine(g *mnkgame.Game, cell mnkgame.Cell, exact bool) bool {
+	for y := range g.Height {
+		for x := range g.Width {
+			for _, d := range []mnkgame.Position{{X: 1}, {Y: 1}, {X: 1, Y: 1}, {X: -1, Y: 1}} {
+				if g.Board[mnkgame.Position{X: x - d.X, Y: y - d.Y}] == cell {
+					continue
+				}
+
+				n := 0
+				for g.Board[mnkgame.Position{X: x + n*d.X, Y: y + n*d.Y}] == cell {
+					n++
+				}
+				if n == g.WinRow || (n > g.WinRow && !exact) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func randomGame(r *rand.Rand, width, height, winRow, stones int) *mnkgame.Game {
+	g := mnkgame.NewGame(width, height, winRow)
+	for range stones {
+		pos := mnkgame.Position{X: r.IntN(width), Y: r.IntN(height)}
+		g.Board[pos] = mnkgame.Cell(1 + r.IntN(2))
+	}
+	return g
+}
+
+func TestHasLineMatchesScan(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	for i := range 500 {
+		width, height := 1+r.IntN(MaxSize), 1+r.IntN(MaxSize)
+		if i%2 == 0 {
+			width, height = 3+r.IntN(8), 3+r.IntN(8)
+		}
+		winRow := 2 + r.IntN(5)
+		g := randomGame(r, width, height, winRow, r.IntN(width*height))
+
+		for _, exact := range []bool{false, true} {
+			if exact {
+				g.Rules = mnkgame.RulesOrderChaos
+			}
+			b, err := FromGame(g)
+			require.Nil(t, err)
+
+			for _, cell := range []mnkgame.Cell{mnkgame.CellX, mnkgame.CellO} {
+				assert.Equal(t, hasLine(g, cell, exact), b.HasLine(cell), "%dx%d k=%d exact=%v %s", width, height, winRow, exact, cell)
+			}
+		}
+	}
+}
+
+func TestLinesDoNotWrapAcrossRows(t *testing.T) {
+	b, err := New(5, 5, 3)
+	require.Nil(t, err)
+
+	require.Nil(t, b.Set(3, 0, mnkgame.CellX))
+	require.Nil(t, b.Set(4, 0, mnkgame.CellX))
+	require.Nil(t, b.Set(0, 1, mnkgame.CellX))
+	assert.False(t, b.HasLine(mnkgame.CellX))
+
+	require.Nil(t, b.Set(0, 0, mnkgame.CellO))
+	require.Nil(t, b.Set(4, 1, mnkgame.CellO))
+	require.Nil(t, b.Set(3, 2, mnkgame.CellO))
+	assert.False(t, b.HasLine(mnkgame.CellO))
+}
+
+func TestLargestBoard(t *testing.T) {
+	b, err := New(MaxSize, MaxSize, 5)
+	require.Nil(t, err)
+
+	for i := range 5 {
+		require.Nil(t, b.Set(MaxSize-1-i, MaxSize-1-i, mnkgame.CellO))
+	}
+	assert.True(t, b.HasLine(mnkgame.CellO))
+	assert.False(t, b.HasLine(mnkgame.CellX))
+
+	_, err = New(MaxSize+1, 3, 3)
+	assert.NotNil(t, err)
+}
+
+func TestPlayAndUndo(t *testing.T) {
+	b, err := New(3, 3, 3)
+	require.Nil(t, err)
+
+	b.Play(1, 1)
+	assert.Equal(t, mnkgame.CellX, b.Cell(1, 1))
+	assert.Equal(t, mnkgame.PlayerO, b.Turn)
+	b.Play(0, 0)
+	assert.Equal(t, mnkgame.CellO, b.Cell(0, 0))
+
+	b.Undo(0, 0)
+	assert.Equal(t, mnkgame.CellEmpty, b.Cell(0, 0))
+	assert.Equal(t, mnkgame.PlayerO, b.Turn)
+
+	empty := b.Empty()
+	assert.Equal(t, 8, empty.Count())
+	assert.False(t, b.Full())
+}
+
+func TestGameRoundTrip(t *testing.T) {
+	g := mnkgame.NewGame(7, 6, 4)
+	for _, pos := range []mnkgame.Position{{X: 3, Y: 5}, {X: 3, Y: 4}, {X: 6, Y: 0}} {
+		require.Nil(t, mnkgame.MakeTurn(g, pos))
+	}
+
+	b, err := FromGame(g)
+	require.Nil(t, err)
+
+	back := b.Game()
+	assert.Equal(t, g.Board, back.Board)
+	assert.Equal(t, g.Key(), back.Key())
+	assert.Equal(t, g.WinRow, back.WinRow)
+}
+
+func TestFromGameRejectsUnpackableGames(t *testing.T) {
+	for _, g := range []*mnkgame.Game{
+		mnkgame.NewInfiniteGame(5),
+		mnkgame.NewHexGame(4, 4),
+		mnkgame.NewCubeGame(4, 4),
+		mnkgame.NewPenteGame(9, 9),
+		mnkgame.NewMultiplayerGame(7, 7, 4, 3, mnkgame.ScoringFirstToK),
+		mnkgame.NewGame(MaxSize+1, MaxSize+1, 5),
+	} {
+		_, err := FromGame(g)
+		assert.NotNil(t, err)
+	}
+}
+
+// The benchmarks play random games to the end on a 15x15 board, once on
+// mnkgame.Game as the engine does and once on a Board.
+func playouts(r *rand.Rand) [][]mnkgame.Position {
+	var games [][]mnkgame.Position
+	for range 16 {
+		moves := make([]mnkgame.Position, 0, 225)
+		for y := range 15 {
+			for x := range 15 {
+				moves = append(moves, mnkgame.Position{X: x, Y: y})
+			}
+		}
+		r.Shuffle(len(moves), func(i, j int) { moves[i], moves[j] = moves[j], moves[i] })
+		games = append(games, moves)
+	}
+	return games
+}
+
+func BenchmarkGamePlayout(b *testing.B) {
+	games := playouts(rand.New(rand.NewPCG(1, 2)))
+
+	for i := 0; b.Loop(); i++ {
+		g := mnkgame.NewGame(15, 15, 5)
+		for _, pos := range games[i%len(games)] {
+			if g.Status != mnkgame.StatusTurn {
+				break
+			}
+			if err := mnkgame.MakeTurn(g, pos); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkBoardPlayout(b *testing.B) {
+	games := playouts(rand.New(rand.NewPCG(1, 2)))
+
+	for i := 0; b.Loop(); i++ {
+		board, err := New(15, 15, 5)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, pos := range games[i%len(games)] {
+			cell := board.Turn.Cell()
+			board.Play(pos.X, pos.Y)
+			if board.HasLine(cell) || board.Full() {
+				break
+			}
+		}
+	}
+}
+
+// The move benchmarks place a stone on every empty cell of a position with
+// 60 stones, look for a line and take the stone back: through MakeTurn and
+// UndoTurn, which only check the lines through the stone, and on a Board.
+func emptyCells(g *mnkgame.Game) []mnkgame.Position {
+	var empty []mnkgame.Position
+	for y := range g.Height {
+		for x := range g.Width {
+			if pos := (mnkgame.Position{X: x, Y: y}); g.Board[pos] == mnkgame.CellEmpty {
+				empty = append(empty, pos)
+			}
+		}
+	}
+	return empty
+}
+
+func BenchmarkGameMove(b *testing.B) {
+	g := randomGame(rand.New(rand.NewPCG(1, 2)), 15, 15, 5, 60)
+	empty := emptyCells(g)
+
+	for b.Loop() {
+		for _, pos := range empty {
+			if err := mnkgame.MakeTurn(g, pos); err != nil {
+				b.Fatal(err)
+			}
+			if err := mnkgame.UndoTurn(g); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkBoardMove(b *testing.B) {
+	g := randomGame(rand.New(rand.NewPCG(1, 2)), 15, 15, 5, 60)
+	empty := emptyCells(g)
+	board, err := FromGame(g)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		for _, pos := range empty {
+			cell := board.Turn.Cell()
+			board.Play(pos.X, pos.Y)
+			board.HasLine(cell)
+			board.Undo(pos.X, pos.Y)
+		}
+	}
+}
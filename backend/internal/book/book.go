@@ -0,0 +1,266 @@
+// Package book is an opening book learned from finished m,n,k games. For
+// the early positions of every game it counts the moves played and how the
+// games ended for the side playing them. Positions are keyed canonically,
+// so openings equal up to a symmetry of the board share their statistics.
+package book
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"webgames/internal/mnkgame"
+)
+
+const (
+	// MaxPlies is the number of turns of a game the book records.
+	MaxPlies = 10
+	// minScore is the score a move needs for Pick to play it.
+	minScore = 0.5
+)
+
+// Default is the book of the games finished on this server.
+var Default = New()
+
+// Check returns an error if the openings of g cannot be recorded: the book
+// needs one stone per turn of the side's own mark on a board where every
+// stone is in sight.
+func Check(g *mnkgame.Game) error {
+	switch {
+	case g.StonesPerTurn != 1 || g.FirstTurnStones != 1:
+		return errors.New("only games placing one stone per turn are supported")
+	case g.Rules == mnkgame.RulesPhantom || g.Rules.ChooseMark():
+		return fmt.Errorf("%s games are not supported", g.Rules)
+	case g.Variant != "":
+		return errors.New("scripted variants are not supported")
+	}
+	return nil
+}
+
+// Stats counts the games a move was played in by how they ended for the
+// side that played it.
+type Stats struct {
+	Games  int
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+// Score returns the share of the points won with the move, a draw counting
+// half.
+func (s Stats) Score() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return (float64(s.Wins) + float64(s.Draws)/2) / float64(s.Games)
+}
+
+// Entry is a move of the book from a position, as placed on the board of
+// the game asking.
+type Entry struct {
+	Position mnkgame.Position
+	Stats
+}
+
+// kind tells apart the games whose positions cannot be compared.
+type kind struct {
+	Width, Height, Depth int
+	WinRow, Players      int
+	Infinite             bool
+	Rules                mnkgame.Rules
+	Scoring              mnkgame.Scoring
+	Topology             mnkgame.Topology
+	Geometry             mnkgame.Geometry
+}
+
+func kindOf(g *mnkgame.Game) kind {
+	return kind{
+		Width:    g.Width,
+		Height:   g.Height,
+		Depth:    g.Depth,
+		WinRow:   g.WinRow,
+		Players:  g.Players,
+		Infinite: g.Infinite,
+		Rules:    g.Rules,
+		Scoring:  g.Scoring,
+		Topology: g.Topology,
+		Geometry: g.Geometry,
+	}
+}
+
+type key struct {
+	kind kind
+	hash uint64
+}
+
+// move is a recorded move. Moves leading to positions equal up to a
+// symmetry are one move, kept in the orientation of the canonical position
+// it was first played from.
+type move struct {
+	pos   mnkgame.Position
+	stats Stats
+}
+
+// Book holds the moves of the recorded positions by the canonical key of
+// the position they lead to.
+type Book struct {
+	mu        sync.Mutex
+	positions map[key]map[uint64]*move
+	games     map[mnkgame.GameID]bool
+}
+
+func New() *Book {
+	return &Book{
+		positions: map[key]map[uint64]*move{},
+		games:     map[mnkgame.GameID]bool{},
+	}
+}
+
+// keyOf returns the book key of the position of g and the symmetry taking
+// it to the canonical position.
+func keyOf(g *mnkgame.Game) (key, mnkgame.Symmetry) {
+	hash, s := g.CanonicalKey()
+	return key{kind: kindOf(g), hash: hash}, s
+}
+
+// Add records the first MaxPlies turns of the finished game g. A game is
+// only recorded once.
+func (b *Book) Add(g *mnkgame.Game) error {
+	if err := Check(g); err != nil {
+		return err
+	}
+	if g.Status == mnkgame.StatusOpponent || g.Status == mnkgame.StatusTurn {
+		return errors.New("game is not over")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.games[g.ID] {
+		return nil
+	}
+
+	// replay the game from its first position
+	c := g.Clone()
+	for len(c.History) > 0 {
+		if err := mnkgame.UndoTurn(c); err != nil {
+			return err
+		}
+	}
+
+	for _, turn := range g.History[:min(len(g.History), MaxPlies)] {
+		pos := turn.Stones[0]
+
+		k, s := keyOf(c)
+		if err := mnkgame.MakeTurn(c, pos); err != nil {
+			return err
+		}
+		next, _ := c.CanonicalKey()
+
+		moves, ok := b.positions[k]
+		if !ok {
+			moves = map[uint64]*move{}
+			b.positions[k] = moves
+		}
+		m, ok := moves[next]
+		if !ok {
+			m = &move{pos: s.Apply(pos, c.Width)}
+			moves[next] = m
+		}
+
+		m.stats.Games++
+		switch result(g, turn.Player) {
+		case 1:
+			m.stats.Wins++
+		case 0:
+			m.stats.Draws++
+		default:
+			m.stats.Losses++
+		}
+	}
+
+	b.games[g.ID] = true
+	return nil
+}
+
+// result returns 1 if the finished game g was won by p, 0 for a draw and
+// -1 if p lost.
+func result(g *mnkgame.Game, p mnkgame.Player) int {
+	switch g.Status {
+	case mnkgame.StatusDraw:
+		return 0
+	case mnkgame.StatusLoss:
+		if g.Loser == p {
+			return -1
+		}
+		return 1
+	}
+
+	if g.Winner == p {
+		return 1
+	}
+	return -1
+}
+
+// Moves returns the recorded moves from the position of g, the most played
+// first.
+func (b *Book) Moves(g *mnkgame.Game) []Entry {
+	if Check(g) != nil {
+		return nil
+	}
+
+	k, s := keyOf(g)
+	inverse := s.Inverse()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := make([]Entry, 0, len(b.positions[k]))
+	for _, m := range b.positions[k] {
+		entries = append(entries, Entry{Position: inverse.Apply(m.pos, g.Width), Stats: m.stats})
+	}
+
+	slices.SortFunc(entries, func(x, y Entry) int {
+		return cmp.Or(
+			cmp.Compare(y.Games, x.Games),
+			cmp.Compare(x.Position.Y, y.Position.Y),
+			cmp.Compare(x.Position.X, y.Position.X),
+		)
+	})
+
+	return entries
+}
+
+// Pick returns a move of the book for the side to move of g, at random
+// among the moves that scored at least half the points, the more often
+// played the likelier.
+func (b *Book) Pick(g *mnkgame.Game) (mnkgame.Position, bool) {
+	if len(g.History) >= MaxPlies {
+		return mnkgame.Position{}, false
+	}
+
+	var good []Entry
+	total := 0
+	for _, e := range b.Moves(g) {
+		if e.Score() >= minScore {
+			good = append(good, e)
+			total += e.Games
+		}
+	}
+	if total == 0 {
+		return mnkgame.Position{}, false
+	}
+
+	n := rand.IntN(total)
+	for _, e := range good {
+		if n < e.Games {
+			return e.Position, true
+		}
+		n -= e.Games
+	}
+
+	return mnkgame.Position{}, false
+}
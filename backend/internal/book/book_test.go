@@ -0,0 +1,73 @@
+package book
+
+import (
+	"testing"
+
+	"webgames/internal/mnkgame"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func play(t *testing.T, id mnkgame.GameID, moves ...mnkgame.Position) *mnkgame.Game {
+	t.Helper()
+
+	g := mnkgame.NewGame(3, 3, 3)
+	g.ID = id
+	for _, pos := range moves {
+		require.Nil(t, mnkgame.MakeTurn(g, pos))
+	}
+	return g
+}
+
+// cornerWin is won by X along the main diagonal, rotated is the same game
+// turned by 180°.
+var (
+	cornerWin = []mnkgame.Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}, {X: 2, Y: 2}}
+	rotated   = []mnkgame.Position{{X: 2, Y: 2}, {X: 1, Y: 2}, {X: 1, Y: 1}, {X: 0, Y: 2}, {X: 0, Y: 0}}
+)
+
+func TestSymmetricGamesShareMoves(t *testing.T) {
+	b := New()
+	require.Nil(t, b.Add(play(t, "a", cornerWin...)))
+	require.Nil(t, b.Add(play(t, "b", rotated...)))
+	require.Nil(t, b.Add(play(t, "b", rotated...)), "a game is recorded once")
+
+	entries := b.Moves(mnkgame.NewGame(3, 3, 3))
+	require.Len(t, entries, 1)
+	assert.Equal(t, Stats{Games: 2, Wins: 2}, entries[0].Stats)
+	assert.Contains(t, []mnkgame.Position{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 2}, {X: 2, Y: 2}}, entries[0].Position)
+
+	// the replies are carried over to the orientation of the asking game
+	entries = b.Moves(play(t, "c", mnkgame.Position{X: 2, Y: 0}))
+	require.Len(t, entries, 1)
+	assert.Equal(t, Stats{Games: 2, Losses: 2}, entries[0].Stats)
+	assert.Contains(t, []mnkgame.Position{{X: 1, Y: 0}, {X: 2, Y: 1}}, entries[0].Position)
+
+	assert.Empty(t, b.Moves(play(t, "d", mnkgame.Position{X: 1, Y: 1})))
+}
+
+func TestPick(t *testing.T) {
+	b := New()
+	require.Nil(t, b.Add(play(t, "a", cornerWin...)))
+
+	pos, ok := b.Pick(mnkgame.NewGame(3, 3, 3))
+	require.True(t, ok)
+	assert.Equal(t, mnkgame.Position{X: 0, Y: 0}, pos)
+
+	// O lost after its reply, so the book has nothing to offer it
+	_, ok = b.Pick(play(t, "b", cornerWin[0]))
+	assert.False(t, ok)
+
+	_, ok = b.Pick(mnkgame.NewGame(4, 4, 3))
+	assert.False(t, ok, "other boards have their own openings")
+}
+
+func TestAddChecks(t *testing.T) {
+	b := New()
+	assert.NotNil(t, b.Add(play(t, "a", cornerWin[:2]...)), "the game is not over")
+
+	g := mnkgame.NewConnectGame(6, 6, 4, 2, 1)
+	assert.NotNil(t, Check(g))
+	assert.NotNil(t, b.Add(g))
+}
@@ -0,0 +1,349 @@
+package dotsboxes
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultSize = 3
+	MaxSize     = 10
+)
+
+type GameID string
+type PlayerID string
+
+// Game is a game of Dots and Boxes on a grid of Width x Height boxes. The
+// sides claim the edges between neighbouring dots, and whoever draws the
+// fourth edge of a box owns it and moves again.
+type Game struct {
+	ID      GameID
+	Seats   []PlayerID
+	Width   int
+	Height  int
+	Edges   map[Edge]Player
+	Boxes   map[Position]Player
+	Scores  map[Player]int
+	Status  Status
+	Turn    Player
+	Winner  Player
+	History []Turn
+}
+
+// Turn is an edge drawn by a side together with the boxes it completed.
+type Turn struct {
+	Player Player
+	Edge   Edge
+	Boxes  []Position
+}
+
+type Player int
+
+const (
+	PlayerA Player = iota + 1
+	PlayerB
+)
+
+func (p Player) Opponent() Player {
+	if p == PlayerA {
+		return PlayerB
+	}
+
+	return PlayerA
+}
+
+var playerName = map[Player]string{
+	PlayerA: "A",
+	PlayerB: "B",
+}
+
+func (p Player) String() string {
+	return playerName[p]
+}
+
+type Status int
+
+const (
+	StatusOpponent Status = iota
+	StatusTurn
+	StatusWin
+	StatusDraw
+)
+
+var statusName = map[Status]string{
+	StatusOpponent: "Need opponent",
+	StatusTurn:     "Turn",
+	StatusWin:      "Win",
+	StatusDraw:     "Draw",
+}
+
+func (s Status) String() string {
+	return statusName[s]
+}
+
+func (g *Game) StatusText() string {
+	switch g.Status {
+	case StatusTurn:
+		return fmt.Sprintf("%s %s", g.Status, g.Turn)
+	case StatusWin:
+		return fmt.Sprintf("%s %s", g.Status, g.Winner)
+	}
+
+	return g.Status.String()
+}
+
+// PlayerID returns the player seated as the given side.
+func (g *Game) PlayerID(p Player) PlayerID {
+	if p < PlayerA || int(p) > len(g.Seats) {
+		return ""
+	}
+
+	return g.Seats[p-1]
+}
+
+// Position addresses a box by the dot in its top left corner.
+type Position struct {
+	X int
+	Y int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+}
+
+// MarshalText lets positions key the boxes when a game is encoded as JSON.
+func (p Position) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Position) UnmarshalText(text []byte) error {
+	*p = Position{}
+	if _, err := fmt.Sscanf(string(text), "(%d,%d)", &p.X, &p.Y); err != nil {
+		return fmt.Errorf("invalid position %q: %w", text, err)
+	}
+
+	return nil
+}
+
+// Edge is the line from dot (X, Y) to the dot to its right, or to the dot
+// below it when Vertical is set.
+type Edge struct {
+	X        int
+	Y        int
+	Vertical bool
+}
+
+func (e Edge) String() string {
+	if e.Vertical {
+		return fmt.Sprintf("(%d,%d)|", e.X, e.Y)
+	}
+
+	return fmt.Sprintf("(%d,%d)-", e.X, e.Y)
+}
+
+// MarshalText lets edges key the drawn lines when a game is encoded as JSON.
+func (e Edge) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+func (e *Edge) UnmarshalText(text []byte) error {
+	*e = Edge{}
+
+	var kind rune
+	if _, err := fmt.Sscanf(string(text), "(%d,%d)%c", &e.X, &e.Y, &kind); err != nil {
+		return fmt.Errorf("invalid edge %q: %w", text, err)
+	}
+
+	switch kind {
+	case '-':
+	case '|':
+		e.Vertical = true
+	default:
+		return fmt.Errorf("invalid edge %q", text)
+	}
+
+	return nil
+}
+
+// InBounds reports whether e joins two dots of the grid.
+func (g *Game) InBounds(e Edge) bool {
+	if e.Vertical {
+		return e.X >= 0 && e.X <= g.Width && e.Y >= 0 && e.Y < g.Height
+	}
+
+	return e.X >= 0 && e.X < g.Width && e.Y >= 0 && e.Y <= g.Height
+}
+
+// sides returns the four edges of the box at pos.
+func sides(pos Position) [4]Edge {
+	return [4]Edge{
+		{X: pos.X, Y: pos.Y},
+		{X: pos.X, Y: pos.Y + 1},
+		{X: pos.X, Y: pos.Y, Vertical: true},
+		{X: pos.X + 1, Y: pos.Y, Vertical: true},
+	}
+}
+
+// boxes returns the boxes on either side of e that lie on the grid.
+func (g *Game) boxes(e Edge) []Position {
+	var boxes []Position
+	if e.Vertical {
+		boxes = []Position{{X: e.X - 1, Y: e.Y}, {X: e.X, Y: e.Y}}
+	} else {
+		boxes = []Position{{X: e.X, Y: e.Y - 1}, {X: e.X, Y: e.Y}}
+	}
+
+	return slices.DeleteFunc(boxes, func(b Position) bool {
+		return b.X < 0 || b.X >= g.Width || b.Y < 0 || b.Y >= g.Height
+	})
+}
+
+func (g *Game) closed(pos Position) bool {
+	for _, e := range sides(pos) {
+		if _, ok := g.Edges[e]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LegalMoves returns the edges not drawn yet.
+func (g *Game) LegalMoves() []Edge {
+	var moves []Edge
+	for y := 0; y <= g.Height; y++ {
+		for x := 0; x <= g.Width; x++ {
+			for _, e := range []Edge{{X: x, Y: y}, {X: x, Y: y, Vertical: true}} {
+				if _, ok := g.Edges[e]; !ok && g.InBounds(e) {
+					moves = append(moves, e)
+				}
+			}
+		}
+	}
+
+	return moves
+}
+
+type CreateGameParams struct {
+	PlayerID string
+	Width    int
+	Height   int
+}
+
+// MakeTurnParams is the edge a player clicked, sent as separate fields
+// rather than in the text form of an Edge.
+type MakeTurnParams struct {
+	X        int
+	Y        int
+	Vertical bool
+}
+
+func (p MakeTurnParams) Edge() Edge {
+	return Edge{X: p.X, Y: p.Y, Vertical: p.Vertical}
+}
+
+var gamesRepository = map[GameID]*Game{}
+
+func CreateGame(params CreateGameParams) *Game {
+	game := NewGame(clampSize(params.Width), clampSize(params.Height))
+	game.Seats = []PlayerID{PlayerID(params.PlayerID)}
+	game.Status = StatusOpponent
+	game.Turn = 0
+
+	gamesRepository[game.ID] = game
+	return game
+}
+
+func clampSize(n int) int {
+	if n < 1 {
+		return DefaultSize
+	}
+
+	return min(n, MaxSize)
+}
+
+func NewGame(width, height int) *Game {
+	return &Game{
+		ID:     GameID(uuid.NewString()),
+		Width:  width,
+		Height: height,
+		Edges:  map[Edge]Player{},
+		Boxes:  map[Position]Player{},
+		Scores: map[Player]int{},
+		Status: StatusTurn,
+		Turn:   PlayerA,
+	}
+}
+
+func FindGame(id GameID) (*Game, bool) {
+	game, ok := gamesRepository[id]
+	return game, ok
+}
+
+func BecomeOpponent(game *Game, playerID PlayerID) error {
+	if game.Status != StatusOpponent {
+		return fmt.Errorf("invalid game status: %s", game.Status)
+	}
+
+	if slices.Contains(game.Seats, playerID) {
+		return errors.New("player has already joined the game")
+	}
+
+	game.Seats = append(game.Seats, playerID)
+	game.Status = StatusTurn
+	game.Turn = PlayerA
+
+	return nil
+}
+
+func MakeTurn(g *Game, e Edge) error {
+	if _, ok := g.Edges[e]; ok || !g.InBounds(e) {
+		return errors.New("Edge is out of bounds or already drawn")
+	}
+
+	if g.Status != StatusTurn {
+		return fmt.Errorf("Game has already ended with status: %s", g.Status)
+	}
+
+	g.Edges[e] = g.Turn
+	turn := Turn{Player: g.Turn, Edge: e}
+	for _, b := range g.boxes(e) {
+		if g.closed(b) {
+			g.Boxes[b] = g.Turn
+			turn.Boxes = append(turn.Boxes, b)
+		}
+	}
+	g.History = append(g.History, turn)
+	g.Scores[g.Turn] += len(turn.Boxes)
+
+	if len(g.Boxes) == g.Width*g.Height {
+		g.finish()
+		return nil
+	}
+
+	// completing a box earns another move
+	if len(turn.Boxes) == 0 {
+		g.Turn = g.Turn.Opponent()
+	}
+
+	return nil
+}
+
+// finish decides the game by the number of boxes owned.
+func (g *Game) finish() {
+	a, b := g.Scores[PlayerA], g.Scores[PlayerB]
+
+	switch {
+	case a > b:
+		g.Status = StatusWin
+		g.Winner = PlayerA
+	case b > a:
+		g.Status = StatusWin
+		g.Winner = PlayerB
+	default:
+		g.Status = StatusDraw
+	}
+}
@@ -0,0 +1,69 @@
+package dotsboxes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGame(t *testing.T) {
+	g := NewGame(3, 2)
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Equal(t, PlayerA, g.Turn)
+	// 3 rows of 3 horizontal edges and 2 rows of 4 vertical ones
+	assert.Len(t, g.LegalMoves(), 17)
+}
+
+func TestBoxEarnsExtraTurn(t *testing.T) {
+	g := NewGame(2, 1)
+
+	require.Nil(t, MakeTurn(g, Edge{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Edge{X: 0, Y: 1}))
+	require.Nil(t, MakeTurn(g, Edge{X: 0, Y: 0, Vertical: true}))
+	assert.Equal(t, PlayerB, g.Turn)
+
+	require.Nil(t, MakeTurn(g, Edge{X: 1, Y: 0, Vertical: true}))
+	assert.Equal(t, PlayerB, g.Turn)
+	assert.Equal(t, PlayerB, g.Boxes[Position{X: 0, Y: 0}])
+	assert.Equal(t, 1, g.Scores[PlayerB])
+	assert.Equal(t, []Position{{X: 0, Y: 0}}, g.History[3].Boxes)
+}
+
+func TestDoubleBoxDecidesGame(t *testing.T) {
+	g := NewGame(2, 1)
+
+	for _, e := range []Edge{
+		{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1},
+		{X: 0, Y: 0, Vertical: true}, {X: 2, Y: 0, Vertical: true},
+	} {
+		require.Nil(t, MakeTurn(g, e))
+	}
+	assert.Equal(t, PlayerA, g.Turn)
+
+	require.Nil(t, MakeTurn(g, Edge{X: 1, Y: 0, Vertical: true}))
+	assert.Equal(t, 2, g.Scores[PlayerA])
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerA, g.Winner)
+	assert.Empty(t, g.LegalMoves())
+}
+
+func TestIllegalEdges(t *testing.T) {
+	g := NewGame(1, 1)
+
+	require.Nil(t, MakeTurn(g, Edge{X: 0, Y: 0}))
+	assert.NotNil(t, MakeTurn(g, Edge{X: 0, Y: 0}))
+	assert.NotNil(t, MakeTurn(g, Edge{X: 1, Y: 0}))
+	assert.NotNil(t, MakeTurn(g, Edge{X: 0, Y: 1, Vertical: true}))
+	assert.Equal(t, PlayerB, g.Turn)
+}
+
+func TestCreateGameClampsSize(t *testing.T) {
+	g := CreateGame(CreateGameParams{PlayerID: "a", Width: 0, Height: 50})
+	assert.Equal(t, DefaultSize, g.Width)
+	assert.Equal(t, MaxSize, g.Height)
+	assert.Equal(t, StatusOpponent, g.Status)
+
+	require.Nil(t, BecomeOpponent(g, "b"))
+	assert.Equal(t, PlayerA, g.Turn)
+}
@@ -0,0 +1,328 @@
+package engine
+
+import (
+	"errors"
+	"math"
+
+	"webgames/internal/book"
+	"webgames/internal/mnkgame"
+	"webgames/internal/solver"
+	"webgames/internal/threats"
+)
+
+const (
+	winScore = 1_000_000_000
+	// nearDistance limits the candidate moves on large boards to the cells
+	// this close to a stone.
+	nearDistance = 2
+	// captureWeight values a captured pair like an open three.
+	captureWeight = 1 << (3 * 3)
+)
+
+// Move is a position together with the mark to place there. An empty mark
+// places the mark of the side to move.
+type Move struct {
+	Position mnkgame.Position
+	Mark     mnkgame.Cell
+}
+
+func (m Move) apply(g *mnkgame.Game) error {
+	return mnkgame.MakeMarkedTurn(g, m.Position, m.Mark)
+}
+
+// BestMove searches the game tree and returns the move for the side to move.
+// Games covered by a tablebase are played perfectly, forced wins found by
+// the threat-space search are played out once the side has a three or four.
+func BestMove(g *mnkgame.Game) (Move, bool) {
+	if s, ok := solver.For(g); ok {
+		if pos, _, ok := s.BestMove(g); ok {
+			return Move{Position: pos}, true
+		}
+	}
+	if threats.Live(g, g.Turn) {
+		if win, ok := threats.FindVCT(g); ok {
+			return Move{Position: win.Moves[0]}, true
+		}
+	}
+
+	move, _, ok := Search(g)
+	return move, ok
+}
+
+// Search runs the game tree search alone and returns the move for the side
+// to move together with its score for that side.
+func Search(g *mnkgame.Game) (Move, int, bool) {
+	moves := candidates(g)
+	if len(moves) == 0 {
+		return Move{}, 0, false
+	}
+
+	s := searcher{me: g.Turn, lines: g.Lines()}
+	depth := searchDepth(len(moves))
+
+	best := moves[0]
+	alpha := math.MinInt
+	for _, m := range moves {
+		c := g.Clone()
+		if err := m.apply(c); err != nil {
+			continue
+		}
+
+		score := s.search(c, depth-1, 1, alpha, math.MaxInt)
+		if score > alpha {
+			alpha = score
+			best = m
+		}
+	}
+
+	return best, alpha, true
+}
+
+// Score searches the position as deep as Search would and returns its score
+// for the given side, whoever is to move.
+func Score(g *mnkgame.Game, me mnkgame.Player) int {
+	s := searcher{me: me, lines: g.Lines()}
+	return s.search(g, searchDepth(len(candidates(g))), 0, math.MinInt, math.MaxInt)
+}
+
+// WinIn returns the score of a game won with the given number of stones
+// still to place.
+func WinIn(stones int) int {
+	return winScore - stones
+}
+
+// IsWin reports whether a score is a won game, IsLoss whether it is a lost one.
+func IsWin(score int) bool {
+	return score > winScore/2
+}
+
+func IsLoss(score int) bool {
+	return score < -winScore/2
+}
+
+// Play makes the moves of the computer seats until a player is to move or
+// the game is over. In Phantom games the computer only sees what its side
+// may see and keeps probing until it finds an empty cell.
+func Play(g *mnkgame.Game) error {
+	for g.Status == mnkgame.StatusTurn && g.PlayerID(g.Turn) == mnkgame.BotID {
+		view := g.ViewAs(g.Turn)
+		move, ok := Opening(view)
+		if !ok {
+			move, ok = BestMove(view)
+		}
+		if !ok {
+			return errors.New("engine found no legal move")
+		}
+
+		err := move.apply(g)
+		if errors.Is(err, mnkgame.ErrProbed) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Opening picks a move from the opening book, so the computer varies its
+// openings, unless a tablebase knows better or threats are on the board.
+func Opening(g *mnkgame.Game) (Move, bool) {
+	pos, ok := book.Default.Pick(g)
+	if !ok {
+		return Move{}, false
+	}
+	if _, ok := solver.For(g); ok {
+		return Move{}, false
+	}
+	// threes and fours on the board call for the search
+	if threats.Live(g, mnkgame.PlayerX) || threats.Live(g, mnkgame.PlayerO) {
+		return Move{}, false
+	}
+
+	return Move{Position: pos}, true
+}
+
+// Evaluate scores the position for the given side: positive values favour
+// it, winScore and -winScore are decided games.
+func Evaluate(g *mnkgame.Game, me mnkgame.Player) int {
+	s := searcher{me: me, lines: g.Lines()}
+	return s.evaluate(g, 0)
+}
+
+func searchDepth(moves int) int {
+	switch {
+	case moves <= 9:
+		return moves
+	case moves <= 16:
+		return 4
+	case moves <= 40:
+		return 3
+	}
+
+	return 2
+}
+
+type searcher struct {
+	me    mnkgame.Player
+	lines [][]mnkgame.Position
+}
+
+// search runs a minimax search with alpha-beta pruning. The turn does not
+// strictly alternate, so maximizing and minimizing follow the side to move.
+func (s *searcher) search(g *mnkgame.Game, depth int, ply int, alpha int, beta int) int {
+	if depth == 0 || g.Status != mnkgame.StatusTurn {
+		return s.evaluate(g, ply)
+	}
+
+	maximizing := g.Turn == s.me
+	best := math.MaxInt
+	if maximizing {
+		best = math.MinInt
+	}
+
+	for _, m := range candidates(g) {
+		c := g.Clone()
+		if err := m.apply(c); err != nil {
+			continue
+		}
+
+		score := s.search(c, depth-1, ply+1, alpha, beta)
+		if maximizing {
+			best = max(best, score)
+			alpha = max(alpha, score)
+		} else {
+			best = min(best, score)
+			beta = min(beta, score)
+		}
+
+		if alpha >= beta {
+			break
+		}
+	}
+
+	return best
+}
+
+// evaluate scores a position, preferring quick wins and slow losses.
+func (s *searcher) evaluate(g *mnkgame.Game, ply int) int {
+	switch g.Status {
+	case mnkgame.StatusWin, mnkgame.StatusLoss:
+		if g.Winner == s.me {
+			return winScore - ply
+		}
+		return -winScore + ply
+	case mnkgame.StatusDraw:
+		return 0
+	}
+
+	// every side places X in Notakto, so open lines favour nobody
+	if g.Rules == mnkgame.RulesNotakto {
+		return 0
+	}
+
+	score := 0
+	for _, line := range s.lines {
+		var owner mnkgame.Cell
+		count := 0
+		for _, pos := range line {
+			cell := g.Board[pos]
+			if cell == mnkgame.CellEmpty {
+				continue
+			}
+			if owner != mnkgame.CellEmpty && cell != owner {
+				count = 0
+				break
+			}
+
+			owner = cell
+			count++
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		// a window only one side has played in is worth more the fuller it is
+		weight := 1 << (3 * count)
+		if g.Rules == mnkgame.RulesOrderChaos {
+			// any single-mark window works towards Order's line
+			if s.me == mnkgame.RoleOrder {
+				score += weight
+			} else {
+				score -= weight
+			}
+		} else if owner == s.me.Cell() {
+			score += weight
+		} else {
+			score -= weight
+		}
+	}
+
+	if g.Rules == mnkgame.RulesMisere {
+		score = -score
+	}
+
+	if g.Rules == mnkgame.RulesPente {
+		for p, pairs := range g.Captures {
+			if p == s.me {
+				score += pairs * captureWeight
+			} else {
+				score -= pairs * captureWeight
+			}
+		}
+	}
+
+	return score
+}
+
+// candidates returns the legal moves worth searching. Large boards are cut
+// down to the cells near the stones already played.
+func candidates(g *mnkgame.Game) []Move {
+	positions := mnkgame.LegalMoves(g)
+
+	if len(positions) > 20 {
+		if len(g.Board) == 0 {
+			positions = positions[len(positions)/2 : len(positions)/2+1]
+		} else {
+			positions = nearStones(g, positions)
+		}
+	}
+
+	marks := []mnkgame.Cell{mnkgame.CellEmpty}
+	if g.Rules.ChooseMark() {
+		marks = []mnkgame.Cell{mnkgame.CellX, mnkgame.CellO}
+	}
+
+	moves := make([]Move, 0, len(positions)*len(marks))
+	for _, pos := range positions {
+		for _, mark := range marks {
+			moves = append(moves, Move{Position: pos, Mark: mark})
+		}
+	}
+
+	return moves
+}
+
+func nearStones(g *mnkgame.Game, positions []mnkgame.Position) []mnkgame.Position {
+	var near []mnkgame.Position
+	for _, m := range positions {
+		for pos := range g.Board {
+			if abs(pos.X-m.X) <= nearDistance && abs(pos.Y-m.Y) <= nearDistance && abs(pos.Z-m.Z) <= nearDistance {
+				near = append(near, m)
+				break
+			}
+		}
+	}
+
+	return near
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"webgames/internal/mnkgame"
+	"webgames/internal/solver"
+	"webgames/internal/threats"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func play(t *testing.T, g *mnkgame.Game, moves ...mnkgame.Position) {
+	t.Helper()
+	for _, m := range moves {
+		require.Nil(t, mnkgame.MakeTurn(g, m))
+	}
+}
+
+func TestBestMoveTakesWin(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	play(t, g,
+		mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 0, Y: 1},
+		mnkgame.Position{X: 1, Y: 0}, mnkgame.Position{X: 1, Y: 1},
+	)
+
+	move, ok := BestMove(g)
+	require.True(t, ok)
+	assert.Equal(t, mnkgame.Position{X: 2, Y: 0}, move.Position)
+}
+
+func TestBestMoveBlocks(t *testing.T) {
+	g := mnkgame.NewGame(7, 7, 4)
+	play(t, g,
+		mnkgame.Position{X: 1, Y: 3}, mnkgame.Position{X: 6, Y: 6},
+		mnkgame.Position{X: 2, Y: 3}, mnkgame.Position{X: 6, Y: 5},
+		mnkgame.Position{X: 3, Y: 3}, mnkgame.Position{X: 0, Y: 3},
+	)
+
+	move, ok := BestMove(g)
+	require.True(t, ok)
+	assert.Equal(t, mnkgame.Position{X: 4, Y: 3}, move.Position)
+}
+
+func TestMisereAvoidsLine(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	g.Rules = mnkgame.RulesMisere
+	play(t, g,
+		mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 2, Y: 2},
+		mnkgame.Position{X: 1, Y: 0}, mnkgame.Position{X: 1, Y: 2},
+	)
+
+	move, ok := BestMove(g)
+	require.True(t, ok)
+	assert.NotEqual(t, mnkgame.Position{X: 2, Y: 0}, move.Position)
+
+	require.Nil(t, move.apply(g))
+	assert.NotEqual(t, mnkgame.StatusLoss, g.Status)
+}
+
+func TestNotaktoAvoidsKillingLastBoard(t *testing.T) {
+	g := mnkgame.NewNotaktoGame(1)
+	play(t, g,
+		mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 1, Y: 0},
+		mnkgame.Position{X: 0, Y: 2}, mnkgame.Position{X: 2, Y: 2},
+	)
+
+	move, ok := BestMove(g)
+	require.True(t, ok)
+	require.Nil(t, move.apply(g))
+	assert.Equal(t, mnkgame.StatusTurn, g.Status)
+}
+
+func TestPlayMovesForBots(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	g.Seats = []mnkgame.PlayerID{"a", mnkgame.BotID}
+
+	play(t, g, mnkgame.Position{X: 1, Y: 1})
+	require.Nil(t, Play(g))
+
+	assert.Equal(t, mnkgame.PlayerX, g.Turn)
+	assert.Len(t, g.Board, 2)
+}
+
+func TestBotsDrawTicTacToe(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	g.Seats = []mnkgame.PlayerID{mnkgame.BotID, mnkgame.BotID}
+
+	require.Nil(t, Play(g))
+	assert.Equal(t, mnkgame.StatusDraw, g.Status)
+}
+
+func TestOrderCompletesLine(t *testing.T) {
+	g := mnkgame.NewOrderChaosGame()
+	for x := range 4 {
+		require.Nil(t, mnkgame.MakeMarkedTurn(g, mnkgame.Position{X: x + 1, Y: 3}, mnkgame.CellO))
+	}
+	require.Nil(t, mnkgame.MakeMarkedTurn(g, mnkgame.Position{X: 0, Y: 0}, mnkgame.CellX))
+	require.Nil(t, mnkgame.MakeMarkedTurn(g, mnkgame.Position{X: 5, Y: 3}, mnkgame.CellX))
+	require.Equal(t, mnkgame.RoleOrder, g.Turn)
+
+	move, ok := BestMove(g)
+	require.True(t, ok)
+	assert.Equal(t, Move{Position: mnkgame.Position{X: 0, Y: 3}, Mark: mnkgame.CellO}, move)
+}
+
+func TestChaosDoesNotCompleteLine(t *testing.T) {
+	g := mnkgame.NewOrderChaosGame()
+	for x := range 3 {
+		require.Nil(t, mnkgame.MakeMarkedTurn(g, mnkgame.Position{X: x + 1, Y: 3}, mnkgame.CellO))
+	}
+	require.Nil(t, mnkgame.MakeMarkedTurn(g, mnkgame.Position{X: 0, Y: 0}, mnkgame.CellX))
+	require.Nil(t, mnkgame.MakeMarkedTurn(g, mnkgame.Position{X: 4, Y: 3}, mnkgame.CellO))
+	require.Equal(t, mnkgame.RoleChaos, g.Turn)
+
+	move, ok := BestMove(g)
+	require.True(t, ok)
+	require.Nil(t, move.apply(g))
+	assert.Equal(t, mnkgame.StatusTurn, g.Status)
+}
+
+func TestPlayPhantomProbesUntilEmptyCell(t *testing.T) {
+	g := mnkgame.NewPhantomGame(3, 3, 3)
+	g.Seats = []mnkgame.PlayerID{"x", mnkgame.BotID}
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 1, Y: 1}))
+
+	require.Nil(t, Play(g))
+	assert.Equal(t, mnkgame.PlayerX, g.Turn)
+	assert.Len(t, g.Board, 2)
+}
+
+func TestBestMoveUsesTablebase(t *testing.T) {
+	g := mnkgame.NewGame(4, 4, 3)
+	c, err := solver.ConfigOf(g)
+	require.Nil(t, err)
+	s, err := solver.New(c)
+	require.Nil(t, err)
+	_, err = s.Solve(context.Background(), g)
+	require.Nil(t, err)
+	solver.Register(s)
+
+	for g.Status == mnkgame.StatusTurn {
+		want, _, ok := s.BestMove(g)
+		require.True(t, ok)
+
+		move, ok := BestMove(g)
+		require.True(t, ok)
+		assert.Equal(t, want, move.Position)
+		require.Nil(t, move.apply(g))
+	}
+
+	// 4x4 with three in a row is a first player win
+	assert.Equal(t, mnkgame.PlayerX, g.Winner)
+}
+
+func TestBestMovePlaysForcedWin(t *testing.T) {
+	g := mnkgame.NewGame(15, 15, 5)
+	// X builds a closed three crossing a two while O plays far away
+	play(t, g,
+		mnkgame.Position{X: 6, Y: 7}, mnkgame.Position{X: 0, Y: 0},
+		mnkgame.Position{X: 7, Y: 7}, mnkgame.Position{X: 14, Y: 14},
+		mnkgame.Position{X: 9, Y: 5}, mnkgame.Position{X: 0, Y: 14},
+		mnkgame.Position{X: 9, Y: 6}, mnkgame.Position{X: 9, Y: 3},
+		mnkgame.Position{X: 9, Y: 4}, mnkgame.Position{X: 14, Y: 0},
+	)
+	require.True(t, threats.Live(g, mnkgame.PlayerX))
+
+	win, ok := threats.FindVCT(g)
+	require.True(t, ok)
+
+	move, ok := BestMove(g)
+	require.True(t, ok)
+	assert.Equal(t, win.Moves[0], move.Position)
+}
@@ -0,0 +1,71 @@
+// Package games is the registry of the kinds of games the server hosts. The
+// web layer only talks to the interfaces below, so a new game plugs in by
+// registering its Type.
+package games
+
+import (
+	"fmt"
+
+	"github.com/a-h/templ"
+)
+
+// Move is a move in the terms of the game it belongs to, such as a cell or
+// an edge. It is decoded by Game.DecodeMove and encodes back to JSON.
+type Move any
+
+// Type is a kind of game. Its games are served under /{Name}/.
+type Type interface {
+	Name() string
+	// Create starts a game for the player from the JSON parameters posted
+	// by the form on the main page.
+	Create(playerID string, params []byte) (Game, error)
+	Find(id string) (Game, bool)
+}
+
+// Game is a single game of some Type. Methods taking a player ID answer for
+// that player, who may be a spectator.
+type Game interface {
+	ID() string
+	// Status describes the state of the game, such as "Turn X".
+	Status() string
+	Over() bool
+	Join(playerID string) error
+	// Active reports whether it is the player's turn.
+	Active(playerID string) bool
+	DecodeMove(data []byte) (Move, error)
+	MakeTurn(move Move) error
+	// LegalMoves lists the moves the player may try, as far as the player
+	// can tell.
+	LegalMoves(playerID string) []Move
+	Page(playerID string) templ.Component
+	// Board is the part of the page updated over SSE.
+	Board(playerID string) templ.Component
+	// Serialize encodes the game as the player may see it as JSON.
+	Serialize(playerID string) ([]byte, error)
+}
+
+var (
+	types = map[string]Type{}
+	order []Type
+)
+
+// Register makes a game type available. It panics if the name is taken, as
+// registration happens once at startup.
+func Register(t Type) {
+	if _, ok := types[t.Name()]; ok {
+		panic(fmt.Sprintf("games: type %q registered twice", t.Name()))
+	}
+
+	types[t.Name()] = t
+	order = append(order, t)
+}
+
+func Lookup(name string) (Type, bool) {
+	t, ok := types[name]
+	return t, ok
+}
+
+// Types returns the registered types in the order they were registered.
+func Types() []Type {
+	return order
+}
@@ -0,0 +1,38 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeType struct {
+	name string
+}
+
+func (t fakeType) Name() string {
+	return t.name
+}
+
+func (fakeType) Create(string, []byte) (Game, error) {
+	return nil, nil
+}
+
+func (fakeType) Find(string) (Game, bool) {
+	return nil, false
+}
+
+func TestRegister(t *testing.T) {
+	Register(fakeType{name: "fake"})
+	Register(fakeType{name: "other"})
+
+	got, ok := Lookup("fake")
+	assert.True(t, ok)
+	assert.Equal(t, "fake", got.Name())
+
+	_, ok = Lookup("missing")
+	assert.False(t, ok)
+
+	assert.Equal(t, []Type{fakeType{name: "fake"}, fakeType{name: "other"}}, Types())
+	assert.Panics(t, func() { Register(fakeType{name: "fake"}) })
+}
@@ -0,0 +1,128 @@
+// Package hints helps beginners in games created with hints enabled: it
+// warns a side of the lines it or another side can complete on the next
+// turn and asks the engine for a move.
+package hints
+
+import (
+	"errors"
+	"fmt"
+
+	"webgames/internal/engine"
+	"webgames/internal/mnkgame"
+)
+
+var ErrDisabled = errors.New("hints are not enabled for this game")
+
+// ErrRated is returned for rated games, which are played without help
+// whatever their settings say.
+var ErrRated = fmt.Errorf("%w: rated games have no hints", ErrDisabled)
+
+// Check returns an error if g offers no hints: they have to be enabled when
+// the game is created and are never given in rated games, and only games where a completed line simply wins
+// and every stone is in sight are supported.
+func Check(g *mnkgame.Game) error {
+	switch {
+	case g.Rated:
+		return ErrRated
+	case !g.Hints:
+		return ErrDisabled
+	case g.Rules != mnkgame.RulesStandard && g.Rules != mnkgame.RulesPente:
+		return fmt.Errorf("hints are not available in %s games", g.Rules)
+	case g.Variant != "":
+		return errors.New("hints are not available in scripted variants")
+	case g.Status != mnkgame.StatusTurn:
+		return errors.New("game is not in progress")
+	}
+	return nil
+}
+
+// Hints are the warnings for one side.
+type Hints struct {
+	// Wins holds the empty cells of the lines the side can complete on its
+	// next turn.
+	Wins []mnkgame.Position
+	// Threats holds the open lines another side can complete on its next
+	// turn: WinRow-1 stones and an empty cell when a turn places one stone.
+	Threats [][]mnkgame.Position
+	// Blocks holds the empty cells of Threats, where the side has to play
+	// not to lose.
+	Blocks []mnkgame.Position
+}
+
+// Find returns the hints for side me of g.
+func Find(g *mnkgame.Game, me mnkgame.Player) (Hints, error) {
+	if err := Check(g); err != nil {
+		return Hints{}, err
+	}
+
+	// the side to move may have placed some of its stones already
+	mine := g.StonesPerTurn
+	if g.Turn == me {
+		mine = g.StonesLeft
+	}
+
+	var h Hints
+	wins := map[mnkgame.Position]bool{}
+	blocks := map[mnkgame.Position]bool{}
+
+	for _, line := range g.Lines() {
+		owner, empty := lineOwner(g, line)
+		switch {
+		case owner == mnkgame.CellEmpty || len(empty) == 0:
+			continue
+		case owner == me.Cell() && len(empty) <= mine:
+			for _, pos := range empty {
+				if !wins[pos] {
+					wins[pos] = true
+					h.Wins = append(h.Wins, pos)
+				}
+			}
+		case owner != me.Cell() && len(empty) <= g.StonesPerTurn:
+			h.Threats = append(h.Threats, line)
+			for _, pos := range empty {
+				if !blocks[pos] {
+					blocks[pos] = true
+					h.Blocks = append(h.Blocks, pos)
+				}
+			}
+		}
+	}
+
+	return h, nil
+}
+
+// lineOwner returns the mark of the only side with stones on line, or an
+// empty cell if there is none or more than one, together with the empty
+// cells of the line.
+func lineOwner(g *mnkgame.Game, line []mnkgame.Position) (mnkgame.Cell, []mnkgame.Position) {
+	owner := mnkgame.CellEmpty
+	var empty []mnkgame.Position
+
+	for _, pos := range line {
+		cell := g.Board[pos]
+		switch {
+		case cell == mnkgame.CellEmpty:
+			empty = append(empty, pos)
+		case owner == mnkgame.CellEmpty:
+			owner = cell
+		case owner != cell:
+			return mnkgame.CellEmpty, nil
+		}
+	}
+
+	return owner, empty
+}
+
+// Suggest asks the engine for a move of the side to move of g.
+func Suggest(g *mnkgame.Game) (engine.Move, error) {
+	if err := Check(g); err != nil {
+		return engine.Move{}, err
+	}
+
+	move, ok := engine.BestMove(g.Clone())
+	if !ok {
+		return engine.Move{}, errors.New("no move found")
+	}
+
+	return move, nil
+}
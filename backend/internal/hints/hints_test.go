@@ -0,0 +1,119 @@
+package hints
+
+import (
+	"testing"
+
+	"webgames/internal/mnkgame"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// play makes the turns on a gomoku board with hints enabled.
+func play(t *testing.T, moves ...mnkgame.Position) *mnkgame.Game {
+	t.Helper()
+
+	g := mnkgame.NewGame(15, 15, 5)
+	g.Hints = true
+	for _, pos := range moves {
+		require.Nil(t, mnkgame.MakeTurn(g, pos))
+	}
+	return g
+}
+
+func TestOpenFour(t *testing.T) {
+	g := play(t,
+		mnkgame.Position{X: 5, Y: 7}, mnkgame.Position{X: 0, Y: 0},
+		mnkgame.Position{X: 6, Y: 7}, mnkgame.Position{X: 1, Y: 0},
+		mnkgame.Position{X: 7, Y: 7}, mnkgame.Position{X: 2, Y: 0},
+		mnkgame.Position{X: 8, Y: 7},
+	)
+	ends := []mnkgame.Position{{X: 4, Y: 7}, {X: 9, Y: 7}}
+
+	h, err := Find(g, mnkgame.PlayerO)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, ends, h.Blocks)
+	assert.Len(t, h.Threats, 2)
+	assert.Empty(t, h.Wins, "three in a row is two stones short")
+
+	h, err = Find(g, mnkgame.PlayerX)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, ends, h.Wins)
+	assert.Empty(t, h.Threats)
+}
+
+func TestSuggestBlocks(t *testing.T) {
+	g := play(t,
+		mnkgame.Position{X: 5, Y: 7}, mnkgame.Position{X: 4, Y: 7},
+		mnkgame.Position{X: 6, Y: 7}, mnkgame.Position{X: 0, Y: 0},
+		mnkgame.Position{X: 7, Y: 7}, mnkgame.Position{X: 1, Y: 0},
+		mnkgame.Position{X: 8, Y: 7},
+	)
+	block := mnkgame.Position{X: 9, Y: 7}
+
+	h, err := Find(g, mnkgame.PlayerO)
+	require.Nil(t, err)
+	assert.Equal(t, []mnkgame.Position{block}, h.Blocks)
+
+	move, err := Suggest(g)
+	require.Nil(t, err)
+	assert.Equal(t, block, move.Position)
+	assert.Empty(t, g.Board[block], "the game is left untouched")
+}
+
+func TestConnectThreats(t *testing.T) {
+	// Connect6: every turn after the first places two stones
+	g := mnkgame.NewConnectGame(19, 19, 6, 2, 1)
+	g.Hints = true
+	for _, pos := range []mnkgame.Position{
+		{X: 9, Y: 9},
+		{X: 0, Y: 0}, {X: 0, Y: 1},
+		{X: 10, Y: 9}, {X: 11, Y: 9},
+		{X: 18, Y: 0}, {X: 18, Y: 1},
+		{X: 12, Y: 9},
+	} {
+		require.Nil(t, mnkgame.MakeTurn(g, pos))
+	}
+
+	h, err := Find(g, mnkgame.PlayerO)
+	require.Nil(t, err)
+	assert.NotEmpty(t, h.Threats, "four of six can be completed with two stones")
+	assert.Contains(t, h.Blocks, mnkgame.Position{X: 13, Y: 9})
+
+	// X has one stone left this turn and cannot complete the four
+	h, err = Find(g, mnkgame.PlayerX)
+	require.Nil(t, err)
+	assert.Empty(t, h.Wins)
+}
+
+func TestCheck(t *testing.T) {
+	g := mnkgame.NewGame(15, 15, 5)
+	assert.ErrorIs(t, Check(g), ErrDisabled)
+
+	g.Hints = true
+	assert.Nil(t, Check(g))
+
+	g.Rules = mnkgame.RulesPhantom
+	assert.NotNil(t, Check(g), "the stones of the other sides are hidden")
+
+	_, err := Suggest(g)
+	assert.NotNil(t, err)
+}
+
+func TestRatedGamesHaveNoHints(t *testing.T) {
+	g := mnkgame.NewGame(15, 15, 5)
+	g.Hints = true
+	g.Rated = true
+	assert.ErrorIs(t, Check(g), ErrRated)
+	assert.ErrorIs(t, Check(g), ErrDisabled)
+
+	_, err := Find(g, mnkgame.PlayerX)
+	assert.ErrorIs(t, err, ErrRated)
+	_, err = Suggest(g)
+	assert.ErrorIs(t, err, ErrRated)
+
+	params := mnkgame.CreateGameParams{Width: 15, Height: 15, WinRow: 5, Hints: true, Rated: true}
+	assert.ErrorIs(t, params.Check(), mnkgame.ErrRatedHints)
+	params.Hints = false
+	assert.Nil(t, params.Check())
+}
@@ -0,0 +1,53 @@
+package mnkgame
+
+import "slices"
+
+// EarlyDraw reports whether a game under the rules is drawn as soon as no
+// side can complete a line, before the board fills up. Captures in Pente
+// reopen lines, and in Notakto and Order and Chaos a line that cannot be
+// completed still decides the game.
+func (r Rules) EarlyDraw() bool {
+	return r == RulesStandard || r == RulesMisere || r == RulesPhantom
+}
+
+// linesClosed reports whether no side still playing can complete a line,
+// so the game can only end in a draw. The window found open last time is
+// checked first, as it usually still is.
+func (g *Game) linesClosed() bool {
+	if g.Infinite || g.Variant != "" || !g.Rules.EarlyDraw() {
+		return false
+	}
+
+	if g.openLine != nil && g.lineOpen(g.openLine) {
+		return false
+	}
+
+	for line := range g.windows(0) {
+		if g.lineOpen(line) {
+			g.openLine = line
+			return false
+		}
+	}
+
+	g.openLine = nil
+	return true
+}
+
+// lineOpen reports whether a side still playing can complete line: it holds
+// the stones of at most one side, and that side has not completed a line
+// already.
+func (g *Game) lineOpen(line []Position) bool {
+	owner := CellEmpty
+	for _, pos := range line {
+		cell := g.Board[pos]
+		switch {
+		case cell == CellEmpty:
+		case owner == CellEmpty:
+			owner = cell
+		case owner != cell:
+			return false
+		}
+	}
+
+	return owner == CellEmpty || !slices.Contains(g.Ranking, Player(owner))
+}
@@ -0,0 +1,86 @@
+package mnkgame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadDraw leaves one empty cell on a 3x3 board, every line through it
+// already holding both marks:
+//
+//	X O X
+//	X O O
+//	O X .
+var deadDraw = []Position{
+	{X: 0, Y: 0}, {X: 1, Y: 1},
+	{X: 2, Y: 0}, {X: 1, Y: 0},
+	{X: 1, Y: 2}, {X: 0, Y: 2},
+	{X: 0, Y: 1}, {X: 2, Y: 1},
+}
+
+func TestDeadBoardIsDrawnEarly(t *testing.T) {
+	for _, rules := range []Rules{RulesStandard, RulesMisere, RulesPhantom} {
+		g := NewGame(3, 3, 3)
+		g.Rules = rules
+
+		last := len(deadDraw) - 1
+		for _, pos := range deadDraw[:last] {
+			require.Nil(t, MakeTurn(g, pos), rules)
+		}
+		// the right column is still open for X
+		assert.Equal(t, StatusTurn, g.Status, rules)
+
+		require.Nil(t, MakeTurn(g, deadDraw[last]), rules)
+		assert.Equal(t, StatusDraw, g.Status, rules)
+		assert.Len(t, g.Board, 8, rules)
+	}
+}
+
+func TestNoLineFits(t *testing.T) {
+	// the rows and columns of a 3x3 torus are too short for 4 in a row
+	g := NewGame(3, 3, 4)
+	g.Topology = TopologyTorus
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	assert.Equal(t, StatusDraw, g.Status)
+}
+
+func TestBlockedLinesOfThreeSides(t *testing.T) {
+	// a 4x1 board with lines of 2, every window is blocked once each side
+	// has played in turn
+	g := NewGame(4, 1, 2)
+	g.Players = 3
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	assert.Equal(t, StatusTurn, g.Status)
+
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 0}))
+	assert.Equal(t, StatusTurn, g.Status, "Z can still complete a line with its next stone")
+
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+	assert.Equal(t, StatusDraw, g.Status)
+}
+
+func TestCapturesKeepPenteGoing(t *testing.T) {
+	// captures free cells again, so Pente is only drawn on a full board
+	g := NewPenteGame(3, 3)
+	g.WinRow = 4
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	assert.Equal(t, StatusTurn, g.Status)
+}
+
+func TestUndoEarlyDraw(t *testing.T) {
+	g := NewGame(3, 3, 3)
+	for _, pos := range deadDraw {
+		require.Nil(t, MakeTurn(g, pos))
+	}
+	require.Equal(t, StatusDraw, g.Status)
+
+	require.Nil(t, UndoTurn(g))
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Equal(t, PlayerO, g.Turn)
+}
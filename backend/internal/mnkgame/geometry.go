@@ -0,0 +1,77 @@
+package mnkgame
+
+import "cmp"
+
+// Geometry is the shape of the cells the board is made of.
+type Geometry int
+
+const (
+	GeometrySquare Geometry = iota
+	// GeometryHex uses axial coordinates: X is the column q and Y the row r,
+	// so (q+1, r) and (q, r+1) and (q+1, r-1) are neighbours. A bounded hex
+	// board is a hexagon stored in a (2*side-1) x (2*side-1) rhombus.
+	GeometryHex
+	// GeometryCube stacks Depth square layers into a cube, lines may run
+	// through the layers in any of the 13 directions of a 3D grid.
+	GeometryCube
+)
+
+var geometryName = map[Geometry]string{
+	GeometrySquare: "Square",
+	GeometryHex:    "Hexagonal",
+	GeometryCube:   "Cube (3D)",
+}
+
+func (g Geometry) String() string {
+	return geometryName[g]
+}
+
+// hexDirections holds the three line directions of a hex grid.
+var hexDirections = []Position{
+	{X: 1, Y: 0},
+	{X: 0, Y: 1},
+	{X: 1, Y: -1},
+}
+
+// cubeDirections holds the 13 line directions of a 3D grid: every non-zero
+// step in {-1, 0, 1}^3 with its first non-zero component positive.
+var cubeDirections = func() []Position {
+	var dirs []Position
+	for dz := -1; dz <= 1; dz++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				d := Position{X: dx, Y: dy, Z: dz}
+				if first := cmp.Or(d.X, d.Y, d.Z); first > 0 {
+					dirs = append(dirs, d)
+				}
+			}
+		}
+	}
+
+	return dirs
+}()
+
+func (g *Game) directions() []Position {
+	switch g.Geometry {
+	case GeometryHex:
+		return hexDirections
+	case GeometryCube:
+		return cubeDirections
+	}
+
+	return squareDirections
+}
+
+// HexSide returns the number of cells along each side of a hex board.
+func (g *Game) HexSide() int {
+	return (g.Width + 1) / 2
+}
+
+// inHexagon reports whether an axial position of the bounding rhombus lies
+// on the hexagon, i.e. cuts off the two corners of the rhombus.
+func (g *Game) inHexagon(pos Position) bool {
+	r := g.HexSide() - 1
+	sum := pos.X + pos.Y
+
+	return sum >= r && sum <= 3*r
+}
@@ -0,0 +1,109 @@
+package mnkgame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHexBoardShape(t *testing.T) {
+	g := NewHexGame(3, 3)
+
+	assert.Equal(t, 19, g.cellCount())
+	assert.False(t, g.InBounds(Position{X: 0, Y: 0}))
+	assert.False(t, g.InBounds(Position{X: 4, Y: 4}))
+	assert.True(t, g.InBounds(Position{X: 2, Y: 0}))
+	assert.True(t, g.InBounds(Position{X: 0, Y: 4}))
+	assert.True(t, g.InBounds(Position{X: 2, Y: 2}))
+
+	assert.NotNil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	assert.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+}
+
+func TestHexWinDirections(t *testing.T) {
+	for _, d := range hexDirections {
+		g := NewHexGame(3, 3)
+		center := Position{X: 2, Y: 2}
+
+		require.Nil(t, MakeTurn(g, Position{X: center.X - d.X, Y: center.Y - d.Y}))
+		require.Nil(t, MakeTurn(g, Position{X: 4, Y: 0}))
+		require.Nil(t, MakeTurn(g, center))
+		require.Nil(t, MakeTurn(g, Position{X: 0, Y: 4}))
+		require.Nil(t, MakeTurn(g, Position{X: center.X + d.X, Y: center.Y + d.Y}))
+
+		assert.Equal(t, StatusWin, g.Status, "direction %v", d)
+		assert.Equal(t, PlayerX, g.Winner)
+	}
+}
+
+func TestHexSquareDiagonalIsNotALine(t *testing.T) {
+	g := NewHexGame(3, 3)
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 4, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 4}))
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 3}))
+
+	assert.Equal(t, StatusTurn, g.Status)
+}
+
+func TestHexLines(t *testing.T) {
+	g := NewHexGame(2, 2)
+
+	// 7 cells: 6 spokes through the center and 6 edges of the ring
+	assert.Len(t, g.Lines(), 12)
+}
+
+func TestCubeDirections(t *testing.T) {
+	assert.Len(t, cubeDirections, 13)
+}
+
+func TestQubicLines(t *testing.T) {
+	g := NewCubeGame(4, 4)
+	assert.Len(t, g.Lines(), 76)
+
+	g = NewCubeGame(3, 3)
+	assert.Len(t, g.Lines(), 49)
+}
+
+func TestCubeBounds(t *testing.T) {
+	g := NewCubeGame(4, 4)
+
+	assert.True(t, g.InBounds(Position{X: 3, Y: 3, Z: 3}))
+	assert.False(t, g.InBounds(Position{X: 0, Y: 0, Z: 4}))
+	assert.NotNil(t, MakeTurn(g, Position{X: 0, Y: 0, Z: -1}))
+
+	flat := NewGame(4, 4, 4)
+	assert.False(t, flat.InBounds(Position{X: 0, Y: 0, Z: 1}))
+}
+
+func TestQubicSpaceDiagonalWin(t *testing.T) {
+	g := NewCubeGame(4, 4)
+
+	for i := range 3 {
+		require.Nil(t, MakeTurn(g, Position{X: i, Y: i, Z: i}))
+		require.Nil(t, MakeTurn(g, Position{X: i, Y: 3 - i, Z: 0}))
+	}
+	assert.Equal(t, StatusTurn, g.Status)
+
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 3, Z: 3}))
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+	assert.Len(t, g.WinLines[0], 4)
+}
+
+func TestQubicVerticalPillarWin(t *testing.T) {
+	g := NewCubeGame(4, 4)
+
+	for z := range 4 {
+		require.Nil(t, MakeTurn(g, Position{X: 1, Y: 2, Z: z}))
+		if z < 3 {
+			require.Nil(t, MakeTurn(g, Position{X: z, Y: 0, Z: 3}))
+		}
+	}
+
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+}
@@ -0,0 +1,184 @@
+package mnkgame
+
+// The Zobrist key of a stone is derived from its coordinates and mark rather
+// than drawn from a table, so keys are the same in every run and cover
+// boards of any size, including infinite ones.
+func zobrist(pos Position, cell Cell) uint64 {
+	h := mix64(uint64(int64(pos.X)) + 0x9e3779b97f4a7c15)
+	h = mix64(h ^ uint64(int64(pos.Y)))
+	h = mix64(h ^ uint64(int64(pos.Z)))
+	return mix64(h ^ uint64(cell))
+}
+
+func turnKey(p Player) uint64 {
+	if p == 0 {
+		return 0
+	}
+	return mix64(uint64(p) ^ 0xd6e8feb86659fd93)
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// Hash computes the Zobrist hash of the stones on the board from scratch.
+// Games keep it in Game.Hash as stones come and go.
+func (b Board) Hash() uint64 {
+	var h uint64
+	for pos, cell := range b {
+		h ^= zobrist(pos, cell)
+	}
+	return h
+}
+
+// put sets the cell at pos, CellEmpty clears it, keeping Hash up to date.
+func (g *Game) put(pos Position, cell Cell) {
+	if old := g.Board[pos]; old != CellEmpty {
+		g.Hash ^= zobrist(pos, old)
+	}
+
+	if cell == CellEmpty {
+		delete(g.Board, pos)
+		return
+	}
+
+	g.Board[pos] = cell
+	g.Hash ^= zobrist(pos, cell)
+}
+
+// Key identifies the position: the stones on the board, the side to move
+// and the pairs every side has captured in Pente.
+func (g *Game) Key() uint64 {
+	return g.Hash ^ turnKey(g.Turn) ^ g.capturesKey()
+}
+
+// capturesKey hashes the capture counts, positions only differing in them
+// are not the same in Pente.
+func (g *Game) capturesKey() uint64 {
+	var h uint64
+	for p, n := range g.Captures {
+		if n > 0 {
+			h ^= mix64(uint64(p)<<32 ^ uint64(n) ^ 0x9e3779b97f4a7c15)
+		}
+	}
+	return h
+}
+
+// Symmetry is one of the 8 symmetries of a square board. On layered boards
+// it is applied to every layer alike.
+type Symmetry int
+
+const (
+	SymmetryIdentity Symmetry = iota
+	SymmetryRotate90
+	SymmetryRotate180
+	SymmetryRotate270
+	SymmetryFlipX
+	SymmetryFlipY
+	SymmetryDiagonal
+	SymmetryAntiDiagonal
+)
+
+var symmetryName = map[Symmetry]string{
+	SymmetryIdentity:     "Identity",
+	SymmetryRotate90:     "Rotate 90°",
+	SymmetryRotate180:    "Rotate 180°",
+	SymmetryRotate270:    "Rotate 270°",
+	SymmetryFlipX:        "Mirror left to right",
+	SymmetryFlipY:        "Mirror top to bottom",
+	SymmetryDiagonal:     "Mirror along the main diagonal",
+	SymmetryAntiDiagonal: "Mirror along the anti-diagonal",
+}
+
+func (s Symmetry) String() string {
+	return symmetryName[s]
+}
+
+// Apply maps pos on a size x size board. Rotations turn clockwise as the
+// board is drawn, with y growing downwards.
+func (s Symmetry) Apply(pos Position, size int) Position {
+	x, y, last := pos.X, pos.Y, size-1
+
+	switch s {
+	case SymmetryRotate90:
+		x, y = last-y, x
+	case SymmetryRotate180:
+		x, y = last-x, last-y
+	case SymmetryRotate270:
+		x, y = y, last-x
+	case SymmetryFlipX:
+		x = last - x
+	case SymmetryFlipY:
+		y = last - y
+	case SymmetryDiagonal:
+		x, y = y, x
+	case SymmetryAntiDiagonal:
+		x, y = last-y, last-x
+	}
+
+	return Position{X: x, Y: y, Z: pos.Z}
+}
+
+// Inverse returns the symmetry undoing s.
+func (s Symmetry) Inverse() Symmetry {
+	switch s {
+	case SymmetryRotate90:
+		return SymmetryRotate270
+	case SymmetryRotate270:
+		return SymmetryRotate90
+	}
+	return s
+}
+
+// Symmetries returns the symmetries mapping the game onto itself, winning
+// lines included. Only square boards with flat or torus topology have more
+// than the identity; scripted variants may break any symmetry, so they get
+// none.
+func (g *Game) Symmetries() []Symmetry {
+	if g.Infinite || g.Width != g.Height || g.Geometry == GeometryHex || g.Variant != "" ||
+		(g.Topology != TopologyFlat && g.Topology != TopologyTorus) {
+		return []Symmetry{SymmetryIdentity}
+	}
+
+	return []Symmetry{
+		SymmetryIdentity,
+		SymmetryRotate90,
+		SymmetryRotate180,
+		SymmetryRotate270,
+		SymmetryFlipX,
+		SymmetryFlipY,
+		SymmetryDiagonal,
+		SymmetryAntiDiagonal,
+	}
+}
+
+// CanonicalKey returns the same key for all positions equal up to the
+// symmetries of the board: the smallest Key among the symmetric images of
+// the position. It also returns the symmetry taking the position to the
+// image with that key, so moves can be carried over with Apply.
+func (g *Game) CanonicalKey() (uint64, Symmetry) {
+	symmetries := g.Symmetries()
+	if len(symmetries) == 1 {
+		return g.Key(), SymmetryIdentity
+	}
+
+	best, bestSymmetry := uint64(0), SymmetryIdentity
+	for i, s := range symmetries {
+		h := turnKey(g.Turn) ^ g.capturesKey()
+		for pos, cell := range g.Board {
+			h ^= zobrist(s.Apply(pos, g.Width), cell)
+		}
+
+		if i == 0 || h < best {
+			best, bestSymmetry = h, s
+		}
+	}
+
+	return best, bestSymmetry
+}
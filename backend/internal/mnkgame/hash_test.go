@@ -0,0 +1,178 @@
+package mnkgame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashFollowsBoard(t *testing.T) {
+	g := NewPenteGame(9, 9)
+	assert.Equal(t, uint64(0), g.Hash)
+
+	for _, pos := range []Position{{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 8, Y: 8}, {X: 3, Y: 1}, {X: 4, Y: 1}} {
+		require.Nil(t, MakeTurn(g, pos))
+		assert.Equal(t, g.Board.Hash(), g.Hash)
+	}
+
+	// the capture took two stones off
+	assert.Len(t, g.Board, 3)
+}
+
+func TestHashIgnoresMoveOrder(t *testing.T) {
+	a := NewGame(5, 5, 4)
+	b := NewGame(5, 5, 4)
+
+	for _, pos := range []Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 2}, {X: 3, Y: 3}} {
+		require.Nil(t, MakeTurn(a, pos))
+	}
+	for _, pos := range []Position{{X: 2, Y: 2}, {X: 3, Y: 3}, {X: 0, Y: 0}, {X: 1, Y: 0}} {
+		require.Nil(t, MakeTurn(b, pos))
+	}
+
+	assert.Equal(t, a.Key(), b.Key())
+}
+
+func TestKeyIncludesSideToMove(t *testing.T) {
+	a := NewGame(3, 3, 3)
+	b := NewGame(3, 3, 3)
+	b.Turn = PlayerO
+
+	assert.Equal(t, a.Hash, b.Hash)
+	assert.NotEqual(t, a.Key(), b.Key())
+}
+
+func TestKeyIncludesCaptures(t *testing.T) {
+	a := NewPenteGame(9, 9)
+	b := NewPenteGame(9, 9)
+	b.Captures[PlayerX] = 1
+
+	assert.NotEqual(t, a.Key(), b.Key())
+	ka, _ := a.CanonicalKey()
+	kb, _ := b.CanonicalKey()
+	assert.NotEqual(t, ka, kb)
+
+	// an empty count is no count
+	b.Captures[PlayerX] = 0
+	assert.Equal(t, a.Key(), b.Key())
+}
+
+func TestUndoTurnRestoresPosition(t *testing.T) {
+	g := NewPenteGame(9, 9)
+	moves := []Position{{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 8, Y: 8}, {X: 3, Y: 1}, {X: 4, Y: 1}}
+
+	var before []*Game
+	for _, pos := range moves {
+		before = append(before, g.Clone())
+		require.Nil(t, MakeTurn(g, pos))
+	}
+
+	for i := len(moves) - 1; i >= 0; i-- {
+		require.Nil(t, UndoTurn(g))
+
+		want := before[i]
+		assert.Equal(t, want.Board, g.Board)
+		assert.Equal(t, want.Hash, g.Hash)
+		assert.Equal(t, want.Turn, g.Turn)
+		assert.Equal(t, want.StonesLeft, g.StonesLeft)
+		assert.Equal(t, want.Captures[PlayerX], g.Captures[PlayerX])
+		assert.Len(t, g.History, len(want.History))
+	}
+
+	assert.Equal(t, ErrNoTurns, UndoTurn(g))
+}
+
+func TestUndoTurnReopensFinishedGame(t *testing.T) {
+	g := NewGame(3, 3, 3)
+	for _, pos := range []Position{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}} {
+		require.Nil(t, MakeTurn(g, pos))
+	}
+	require.Equal(t, StatusWin, g.Status)
+
+	require.Nil(t, UndoTurn(g))
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Equal(t, PlayerX, g.Turn)
+	assert.Equal(t, Player(0), g.Winner)
+	assert.Empty(t, g.WinLines)
+	assert.Empty(t, g.Ranking)
+
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2}))
+	assert.Equal(t, PlayerO, g.Turn)
+}
+
+func TestUndoTurnWithinConnectTurn(t *testing.T) {
+	g := NewConnectGame(19, 19, 6, 2, 1)
+	require.Nil(t, MakeTurn(g, Position{X: 9, Y: 9}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 1}))
+	require.Equal(t, PlayerX, g.Turn)
+
+	require.Nil(t, UndoTurn(g))
+	assert.Equal(t, PlayerO, g.Turn)
+	assert.Equal(t, 1, g.StonesLeft)
+	assert.Len(t, g.History, 2)
+
+	require.Nil(t, UndoTurn(g))
+	assert.Equal(t, PlayerO, g.Turn)
+	assert.Equal(t, 2, g.StonesLeft)
+	assert.Len(t, g.History, 1)
+}
+
+func TestSymmetryInverse(t *testing.T) {
+	pos := Position{X: 1, Y: 3, Z: 2}
+	for _, s := range NewGame(5, 5, 4).Symmetries() {
+		assert.Equal(t, pos, s.Inverse().Apply(s.Apply(pos, 5), 5), s.String())
+	}
+}
+
+func TestCanonicalKeySymmetricPositions(t *testing.T) {
+	moves := []Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 3, Y: 1}}
+
+	base := NewGame(5, 5, 4)
+	for _, pos := range moves {
+		require.Nil(t, MakeTurn(base, pos))
+	}
+	want, _ := base.CanonicalKey()
+
+	for _, s := range base.Symmetries() {
+		g := NewGame(5, 5, 4)
+		for _, pos := range moves {
+			require.Nil(t, MakeTurn(g, s.Apply(pos, 5)))
+		}
+
+		key, canonical := g.CanonicalKey()
+		assert.Equal(t, want, key, s.String())
+
+		// the returned symmetry leads to the image with that key
+		image := NewGame(5, 5, 4)
+		for _, pos := range moves {
+			require.Nil(t, MakeTurn(image, canonical.Apply(s.Apply(pos, 5), 5)))
+		}
+		assert.Equal(t, key, image.Key(), s.String())
+	}
+
+	other := NewGame(5, 5, 4)
+	for _, pos := range []Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 3, Y: 2}} {
+		require.Nil(t, MakeTurn(other, pos))
+	}
+	key, _ := other.CanonicalKey()
+	assert.NotEqual(t, want, key)
+}
+
+func TestSymmetriesOfAsymmetricBoards(t *testing.T) {
+	assert.Len(t, NewGame(5, 5, 4).Symmetries(), 8)
+	assert.Len(t, NewGame(7, 5, 4).Symmetries(), 1)
+	assert.Len(t, NewInfiniteGame(5).Symmetries(), 1)
+	assert.Len(t, NewHexGame(4, 4).Symmetries(), 1)
+
+	g := NewGame(5, 5, 4)
+	g.Topology = TopologyWrapX
+	assert.Len(t, g.Symmetries(), 1)
+
+	g = NewGame(7, 5, 4)
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	key, s := g.CanonicalKey()
+	assert.Equal(t, g.Key(), key)
+	assert.Equal(t, SymmetryIdentity, s)
+}
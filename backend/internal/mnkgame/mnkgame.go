@@ -3,6 +3,7 @@ package mnkgame
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/google/uuid"
 )
@@ -10,33 +11,228 @@ import (
 type GameID string
 type PlayerID string
 
+var (
+	ErrOutOfBounds = errors.New("Position is out of bounds")
+	ErrOccupied    = errors.New("Cell is already occupied")
+	// ErrProbed is returned in Phantom when the cell holds a hidden stone of
+	// another side. The mover learns what is there and keeps the turn.
+	ErrProbed     = fmt.Errorf("%w by a hidden stone", ErrOccupied)
+	ErrRatedHints = errors.New("Rated games cannot have hints")
+)
+
+// BotID is the seat of a side played by the computer.
+const BotID PlayerID = "bot"
+
 type Game struct {
-	ID        GameID
-	PlayerXID PlayerID
-	PlayerOID PlayerID
-	Board     [][]Cell
-	Status    Status
-	WinRow    int
-	History   []Position
+	ID GameID
+	// Seats lists the joined players in turn order, seat i plays Player(i+1).
+	Seats   []PlayerID
+	Players int
+	Board   Board
+	// Hash is the Zobrist hash of the stones on the board, kept up to date
+	// as they are placed, captured and taken back.
+	Hash uint64
+	// Width and Height bound the board unless it is Infinite. Depth is the
+	// number of layers of a 3D board, flat boards have a single layer.
+	Width    int
+	Height   int
+	Depth    int
+	Infinite bool
+	Status   Status
+	// Turn is the side to move while the game is in progress.
+	Turn    Player
+	Winner  Player
+	Loser   Player
+	Scoring Scoring
+	// Ranking lists the sides in the order they completed a line when
+	// playing with ScoringElimination.
+	Ranking []Player
+	WinRow  int
+	// WinLines holds the completed lines, each ordered from one end to the other.
+	WinLines [][]Position
+	Rules    Rules
+	// Captures counts the captured pairs of every side in Pente, a side
+	// reaching CaptureWin pairs wins.
+	Captures   map[Player]int
+	CaptureWin int
+	// Probes lists the occupied cells every side tried to play in Phantom.
+	Probes map[Player][]Position
+	// Variant names the registered Variant deciding the game, if any.
+	Variant string
+	// Hints lets the players see the threats on the board and ask the
+	// engine for a move.
+	Hints bool
+	// Rated games are played without help, so they never have hints.
+	Rated bool
+	// Blocked holds the cells cut out of the board by the variant's shape.
+	Blocked  map[Position]bool
+	Topology Topology
+	Geometry Geometry
+	// Connect(m,n,k,p,q): X places FirstTurnStones (q) stones on the first
+	// turn, every later turn places StonesPerTurn (p) stones.
+	FirstTurnStones int
+	StonesPerTurn   int
+	// StonesLeft is the number of stones the side to move still has to place
+	// before the turn passes to the next player.
+	StonesLeft int
+	History    []Turn
+	// openLine is the last window found that a side can still complete,
+	// checked first when looking for an early draw.
+	openLine []Position
+}
+
+// InBounds reports whether a stone may be placed at pos.
+func (g *Game) InBounds(pos Position) bool {
+	if pos.Z < 0 || pos.Z >= g.Layers() {
+		return false
+	}
+
+	if g.Infinite {
+		return g.nearStone(pos)
+	}
+
+	if pos.X < 0 || pos.X >= g.Width || pos.Y < 0 || pos.Y >= g.Height {
+		return false
+	}
+
+	if g.Geometry == GeometryHex {
+		return g.inHexagon(pos)
+	}
+
+	return !g.Blocked[pos]
+}
+
+// InfiniteReach is how far from the nearest stone, or from the origin on an
+// empty board, a stone may be placed on an infinite board. It keeps the
+// board from spreading out faster than the stones are placed.
+const InfiniteReach = 10
+
+// nearStone reports whether pos is within reach of a stone on an infinite
+// board. The reach grows with WinRow, so the windows of long lines around
+// the stones stay on the board.
+func (g *Game) nearStone(pos Position) bool {
+	reach := max(InfiniteReach, 2*g.WinRow)
+	near := func(p Position) bool {
+		return abs(pos.X-p.X) <= reach && abs(pos.Y-p.Y) <= reach
+	}
+
+	if len(g.Board) == 0 {
+		return near(Position{})
+	}
+	for p := range g.Board {
+		if near(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// cellCount returns the number of cells on a bounded board.
+func (g *Game) cellCount() int {
+	if g.Geometry == GeometryHex {
+		side := g.HexSide()
+		return 3*side*(side-1) + 1
+	}
+
+	return g.Width*g.Height*g.Layers() - len(g.Blocked)
+}
+
+// Layers returns the number of layers of the board.
+func (g *Game) Layers() int {
+	return max(g.Depth, 1)
+}
+
+// Viewport returns the top-left and bottom-right corners of the part of the
+// board to show. Infinite boards show the occupied cells and margin empty
+// cells around them, bounded boards are always shown whole.
+func (g *Game) Viewport(margin int) (Position, Position) {
+	if !g.Infinite {
+		return Position{}, Position{X: g.Width - 1, Y: g.Height - 1}
+	}
+
+	if len(g.Board) == 0 {
+		return Position{X: -margin, Y: -margin}, Position{X: margin, Y: margin}
+	}
+
+	first := true
+	var lo, hi Position
+	for pos := range g.Board {
+		if first {
+			lo, hi, first = pos, pos, false
+		}
+
+		lo = Position{X: min(lo.X, pos.X), Y: min(lo.Y, pos.Y)}
+		hi = Position{X: max(hi.X, pos.X), Y: max(hi.Y, pos.Y)}
+	}
+
+	return Position{X: lo.X - margin, Y: lo.Y - margin}, Position{X: hi.X + margin, Y: hi.Y + margin}
+}
+
+// PlayerID returns the player seated as the given side.
+func (g *Game) PlayerID(p Player) PlayerID {
+	if p < PlayerX || int(p) > len(g.Seats) {
+		return ""
+	}
+
+	return g.Seats[p-1]
 }
 
-func (g *Game) Width() int {
-	return len(g.Board[0])
+// Seat returns the side played by the given player.
+func (g *Game) Seat(id PlayerID) (Player, bool) {
+	i := slices.Index(g.Seats, id)
+	if i < 0 {
+		return 0, false
+	}
+
+	return Player(i + 1), true
 }
 
-func (g *Game) Height() int {
-	return len(g.Board)
+func (g *Game) StatusText() string {
+	switch g.Status {
+	case StatusTurn:
+		return fmt.Sprintf("%s %s", g.Status, g.SideName(g.Turn))
+	case StatusWin:
+		return fmt.Sprintf("%s %s", g.Status, g.SideName(g.Winner))
+	case StatusLoss:
+		return fmt.Sprintf("%s %s, win %s", g.Status, g.SideName(g.Loser), g.SideName(g.Winner))
+	}
+
+	return g.Status.String()
 }
 
-type Board [][]Cell
+// Board holds the occupied cells, empty cells are not stored.
+type Board map[Position]Cell
 
 type Player int
 
 const (
-	PlayerX Player = 1
-	PlayerO Player = 2
+	PlayerX Player = iota + 1
+	PlayerO
+	PlayerTriangle
+	PlayerSquare
 )
 
+const MaxPlayers = 4
+
+const MaxCubeSize = 8
+
+const MaxNotaktoBoards = 5
+
+func (p Player) Cell() Cell {
+	return Cell(p)
+}
+
+func (p Player) String() string {
+	return p.Cell().String()
+}
+
 type Cell int
 
 func (c Cell) String() string {
@@ -47,6 +243,10 @@ func (c Cell) String() string {
 		return "X"
 	case 2:
 		return "O"
+	case 3:
+		return "△"
+	case 4:
+		return "□"
 	}
 
 	return ""
@@ -56,91 +256,355 @@ const (
 	CellEmpty Cell = iota
 	CellX
 	CellO
+	CellTriangle
+	CellSquare
 )
 
 type Status int
 
 const (
 	StatusOpponent Status = iota
-	StatusTurnX
-	StatusTurnO
-	StatusWinX
-	StatusWinO
+	StatusTurn
+	StatusWin
 	StatusDraw
+	// StatusLoss ends misère games, Loser is the side that completed a line.
+	StatusLoss
 )
 
 var statusName = map[Status]string{
 	StatusOpponent: "Need opponent",
-	StatusTurnX:    "Turn X",
-	StatusTurnO:    "Turn O",
-	StatusWinX:     "Win X",
-	StatusWinO:     "Win O",
+	StatusTurn:     "Turn",
+	StatusWin:      "Win",
 	StatusDraw:     "Draw",
+	StatusLoss:     "Loss",
 }
 
 func (s Status) String() string {
 	return statusName[s]
 }
 
+// Scoring decides how games with more than two sides end.
+type Scoring int
+
+const (
+	// ScoringFirstToK ends the game as soon as any side completes a line.
+	ScoringFirstToK Scoring = iota
+	// ScoringElimination takes a side that completed a line out of the
+	// rotation and plays on until a single side is left, ranking all sides.
+	ScoringElimination
+)
+
+var scoringName = map[Scoring]string{
+	ScoringFirstToK:    "First to K",
+	ScoringElimination: "Elimination",
+}
+
+func (s Scoring) String() string {
+	return scoringName[s]
+}
+
 type Position struct {
 	X int
 	Y int
+	// Z is the layer of a 3D board and always 0 on flat boards.
+	Z int
+}
+
+// String formats the position with signed coordinates, which also works for
+// cells left of or above the first stone on an infinite board. The layer is
+// only included for positions above the first one.
+func (p Position) String() string {
+	if p.Z != 0 {
+		return fmt.Sprintf("(%d,%d,%d)", p.X, p.Y, p.Z)
+	}
+
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+}
+
+// MarshalText lets positions key the board when a game is encoded as JSON.
+func (p Position) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Position) UnmarshalText(text []byte) error {
+	*p = Position{}
+	if _, err := fmt.Sscanf(string(text), "(%d,%d,%d)", &p.X, &p.Y, &p.Z); err == nil {
+		return nil
+	}
+
+	*p = Position{}
+	if _, err := fmt.Sscanf(string(text), "(%d,%d)", &p.X, &p.Y); err != nil {
+		return fmt.Errorf("invalid position %q: %w", text, err)
+	}
+
+	return nil
+}
+
+// Turn groups the stones placed by one side before the turn passed.
+type Turn struct {
+	Player Player
+	Stones []Position
+	// Captured lists the stones removed from the board during the turn.
+	Captured []Stone
+}
+
+// Stone is a mark at a position of the board.
+type Stone struct {
+	Position Position
+	Cell     Cell
 }
 
 type CreateGameParams struct {
-	PlayerXID string
-	Width     int
-	Height    int
-	WinRow    int
+	PlayerID string
+	Players  int
+	Scoring  Scoring
+	Rules    Rules
+	Topology Topology
+	Geometry Geometry
+	Infinite bool
+	Width    int
+	Height   int
+	WinRow   int
+	// Boards is the number of boards of a Notakto game.
+	Boards int
+	// Bot fills the remaining seats with the computer.
+	Bot             bool
+	FirstTurnStones int
+	StonesPerTurn   int
+	// Variant selects a registered Variant by name, overriding the board
+	// settings above.
+	Variant string
+	Hints   bool
+	Rated   bool
+}
+
+// Check returns an error if params ask for a game that cannot be created.
+func (params CreateGameParams) Check() error {
+	if params.Rated && params.Hints {
+		return ErrRatedHints
+	}
+	return nil
 }
 
 type MakeTurnParams struct {
 	X int
 	Y int
+	Z int
+	// Mark is the mark chosen by the mover, empty for the side's own mark.
+	Mark Cell
+}
+
+func (p MakeTurnParams) Position() Position {
+	return Position{X: p.X, Y: p.Y, Z: p.Z}
 }
 
 var gamesRepository = map[GameID]*Game{
 	"9f4ef5fb-d1ce-4ecd-aa7c-3c5ba02bc0a7": {
-		ID:        "9f4ef5fb-d1ce-4ecd-aa7c-3c5ba02bc0a7",
-		PlayerXID: "74273137-5d5b-48b1-910c-9718afae8ae6",
-		PlayerOID: "",
-		WinRow:    3,
-		Board: [][]Cell{
-			{0, 0, 0},
-			{0, 0, 0},
-			{0, 0, 0},
-		},
+		ID:              "9f4ef5fb-d1ce-4ecd-aa7c-3c5ba02bc0a7",
+		Seats:           []PlayerID{"74273137-5d5b-48b1-910c-9718afae8ae6"},
+		Players:         2,
+		WinRow:          3,
+		Board:           Board{},
+		Width:           3,
+		Height:          3,
+		Captures:        map[Player]int{},
+		FirstTurnStones: 1,
+		StonesPerTurn:   1,
+		StonesLeft:      1,
 	},
 }
 
 func CreateGame(params CreateGameParams) *Game {
-	board := make(Board, params.Height)
-	for i := range board {
-		board[i] = make([]Cell, params.Width)
+	game := NewGameFromParams(params)
+	game.Seats = []PlayerID{PlayerID(params.PlayerID)}
+	game.Status = StatusOpponent
+	game.Turn = 0
+	if params.Bot {
+		for len(game.Seats) < game.Players {
+			game.Seats = append(game.Seats, BotID)
+		}
+		game.Status = StatusTurn
+		game.Turn = PlayerX
 	}
 
-	game := &Game{
-		ID:        GameID(uuid.NewString()),
-		PlayerXID: PlayerID(params.PlayerXID),
-		Board:     board,
-		WinRow:    params.WinRow,
+	gamesRepository[game.ID] = game
+	return game
+}
+
+// ImportGame stores a game built elsewhere, e.g. loaded from a record, under
+// a new ID. The player takes every seat, so they can play on from the
+// position or step through it.
+func ImportGame(game *Game, playerID PlayerID) *Game {
+	game.ID = GameID(uuid.NewString())
+	game.Seats = make([]PlayerID, game.Players)
+	for i := range game.Seats {
+		game.Seats[i] = playerID
 	}
 
 	gamesRepository[game.ID] = game
 	return game
 }
 
-func NewGame(width int, height int, winRow int) *Game {
-	board := make(Board, height)
-	for i := range board {
-		board[i] = make([]Cell, width)
+// HostGame stores a game set up elsewhere, e.g. in the position editor,
+// under a new ID with the player in the first seat. The other seats wait
+// for opponents or go to the computer if bot is set. The side to move is
+// kept.
+func HostGame(game *Game, playerID PlayerID, bot bool) *Game {
+	game.ID = GameID(uuid.NewString())
+	game.Seats = []PlayerID{playerID}
+	game.Status = StatusOpponent
+	if bot {
+		for len(game.Seats) < game.Players {
+			game.Seats = append(game.Seats, BotID)
+		}
+		game.Status = StatusTurn
+	}
+
+	gamesRepository[game.ID] = game
+	return game
+}
+
+// NewGameFromParams creates a game with the board and rules of params and
+// X to move. It takes no seats and is not stored.
+func NewGameFromParams(params CreateGameParams) *Game {
+	game := NewConnectGame(params.Width, params.Height, params.WinRow,
+		params.StonesPerTurn, params.FirstTurnStones)
+	game.Players = min(max(params.Players, 2), MaxPlayers)
+	game.Scoring = params.Scoring
+	game.Rules = params.Rules
+	game.Topology = params.Topology
+	game.Hints = params.Hints
+	game.Rated = params.Rated
+	if params.Geometry == GeometryHex {
+		// the width is the side of the hexagon
+		side := max(params.Width, 2)
+		game.Geometry = GeometryHex
+		game.Width = 2*side - 1
+		game.Height = 2*side - 1
+		game.Topology = TopologyFlat
+	}
+	if params.Geometry == GeometryCube {
+		// the width is the edge of the cube, rendered as stacked layers
+		game.Geometry = GeometryCube
+		game.Width = min(game.Width, MaxCubeSize)
+		game.Height = game.Width
+		game.Depth = game.Width
+		game.Topology = TopologyFlat
+		params.Infinite = false
+	}
+	if params.Rules.TwoSided() {
+		game.Players = 2
 	}
+	if params.Rules == RulesNotakto {
+		// Notakto boards are the layers of a flat board
+		game.Geometry = GeometrySquare
+		game.Depth = min(max(params.Boards, 1), MaxNotaktoBoards)
+		params.Infinite = false
+	}
+	if variant, ok := FindVariant(params.Variant); ok {
+		// variants shape a plain two-player board themselves
+		game.Variant = variant.Name()
+		game.Rules = RulesStandard
+		game.Players = 2
+		game.Geometry = GeometrySquare
+		game.Topology = TopologyFlat
+		game.Depth = 0
+		params.Infinite = false
+		variant.Setup(game)
+	}
+	if params.Infinite {
+		game.Infinite = true
+		game.Width = 0
+		game.Height = 0
+		game.Topology = TopologyFlat
+	}
+
+	return game
+}
+
+func NewGame(width int, height int, winRow int) *Game {
+	return NewConnectGame(width, height, winRow, 1, 1)
+}
+
+// NewConnectGame creates a Connect(m,n,k,p,q) game: X opens with q stones,
+// then each side places p stones per turn. Connect6 is Connect(19,19,6,2,1).
+func NewConnectGame(m, n, k, p, q int) *Game {
+	p = max(p, 1)
+	q = max(q, 1)
 
 	return &Game{
-		ID:     GameID(uuid.NewString()),
-		Board:  board,
-		WinRow: winRow,
+		ID:              GameID(uuid.NewString()),
+		Players:         2,
+		Board:           Board{},
+		Width:           m,
+		Height:          n,
+		Status:          StatusTurn,
+		Turn:            PlayerX,
+		WinRow:          k,
+		Captures:        map[Player]int{},
+		CaptureWin:      DefaultCaptureWin,
+		FirstTurnStones: q,
+		StonesPerTurn:   p,
+		StonesLeft:      q,
+	}
+}
+
+// NewInfiniteGame creates a two-player game on a board without edges.
+func NewInfiniteGame(winRow int) *Game {
+	game := NewGame(0, 0, winRow)
+	game.Infinite = true
+
+	return game
+}
+
+// NewHexGame creates a two-player game on a hexagon with the given side.
+func NewHexGame(side int, winRow int) *Game {
+	game := NewGame(2*side-1, 2*side-1, winRow)
+	game.Geometry = GeometryHex
+
+	return game
+}
+
+// NewCubeGame creates a two-player game on a size x size x size cube, e.g.
+// Qubic is NewCubeGame(4, 4).
+func NewCubeGame(size int, winRow int) *Game {
+	game := NewGame(size, size, winRow)
+	game.Depth = size
+	game.Geometry = GeometryCube
+
+	return game
+}
+
+// NewMultiplayerGame creates a game for the given number of sides which is
+// played without seats, e.g. for tests or local play.
+func NewMultiplayerGame(width, height, winRow, players int, scoring Scoring) *Game {
+	game := NewGame(width, height, winRow)
+	game.Players = min(max(players, 2), MaxPlayers)
+	game.Scoring = scoring
+
+	return game
+}
+
+// turnStones returns the number of stones to place on the turn with the given index.
+func (g *Game) turnStones(turn int) int {
+	if turn == 0 {
+		return g.FirstTurnStones
+	}
+
+	return g.StonesPerTurn
+}
+
+// nextPlayer returns the side after p in the rotation, skipping the sides
+// that have already completed a line.
+func (g *Game) nextPlayer(p Player) Player {
+	for range g.Players {
+		p = p%Player(g.Players) + 1
+		if !slices.Contains(g.Ranking, p) {
+			return p
+		}
 	}
+
+	return p
 }
 
 func BecomeOpponent(game *Game, playerID PlayerID) error {
@@ -148,8 +612,18 @@ func BecomeOpponent(game *Game, playerID PlayerID) error {
 		return fmt.Errorf("invalid game status: %s", game.Status)
 	}
 
-	game.PlayerOID = playerID
-	game.Status = StatusTurnX
+	if slices.Contains(game.Seats, playerID) {
+		return errors.New("player has already joined the game")
+	}
+
+	game.Seats = append(game.Seats, playerID)
+	if len(game.Seats) == game.Players {
+		game.Status = StatusTurn
+		// a game set up from a position may start with another side
+		if game.Turn == 0 {
+			game.Turn = PlayerX
+		}
+	}
 
 	return nil
 }
@@ -160,138 +634,183 @@ func FindGame(id GameID) (*Game, bool) {
 }
 
 func MakeTurn(g *Game, pos Position) error {
-	if pos.X < 0 || pos.X >= g.Width() ||
-		pos.Y < 0 || pos.Y >= g.Height() ||
-		g.Board[pos.Y][pos.X] != CellEmpty {
-		return errors.New("Position is out of bounds or cell is already occupied")
-	}
+	return MakeMarkedTurn(g, pos, CellEmpty)
+}
 
-	// set cell or return error if game has already ended
-	var cell Cell
-	switch g.Status {
-	case StatusTurnX:
-		cell = CellX
-	case StatusTurnO:
-		cell = CellO
-	default:
+// MakeMarkedTurn places the given mark at pos. CellEmpty stands for the mark
+// of the side to move; other marks are only allowed where the rules let the
+// mover choose, as in Order and Chaos.
+func MakeMarkedTurn(g *Game, pos Position, mark Cell) error {
+	if g.Status != StatusTurn {
 		return fmt.Errorf("Game has already ended with status: %s", g.Status)
 	}
 
-	g.Board[pos.Y][pos.X] = cell
-	g.History = append(g.History, pos)
+	if !g.InBounds(pos) {
+		return ErrOutOfBounds
+	}
+
+	if cell := g.Board[pos]; cell != CellEmpty {
+		// in Phantom the mover learns what is there and tries again
+		if g.Rules == RulesPhantom && cell != g.mark(g.Turn) {
+			g.probe(g.Turn, pos)
+			return ErrProbed
+		}
+
+		return ErrOccupied
+	}
+
+	if g.Rules == RulesNotakto && g.deadLayer(pos.Z) {
+		return fmt.Errorf("Board %d is already dead", pos.Z+1)
+	}
 
-	win := checkWin(g, pos, cell)
+	variant, scripted := g.variant()
+	if scripted {
+		ok, err := variant.Legal(g, pos)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s does not allow a move at %s", variant.Name(), pos)
+		}
+	}
 
-	if win {
-		if cell == CellX {
-			g.Status = StatusWinX
-		} else {
-			g.Status = StatusWinO
+	player := g.Turn
+	cell := g.mark(player)
+	if mark != CellEmpty && mark != cell {
+		if g.Rules != RulesOrderChaos || (mark != CellX && mark != CellO) {
+			return fmt.Errorf("%s cannot place %s", g.SideName(player), mark)
 		}
 
-		return nil
+		cell = mark
 	}
 
-	// check draw condition
-	if len(g.History) == g.Width()*g.Height() {
-		g.Status = StatusDraw
-		return nil
+	// the first stone of a turn opens a new history entry
+	if g.StonesLeft == g.turnStones(len(g.History)) {
+		g.History = append(g.History, Turn{Player: player})
 	}
 
-	// switch the player for the next move
-	if cell == CellX {
-		g.Status = StatusTurnO
-	} else {
-		g.Status = StatusTurnX
+	turn := &g.History[len(g.History)-1]
+	turn.Stones = append(turn.Stones, pos)
+	g.put(pos, cell)
+	g.StonesLeft--
+
+	if g.Rules == RulesPente {
+		captured := capture(g, pos, cell)
+		turn.Captured = append(turn.Captured, captured...)
+		g.Captures[player] += len(captured) / 2
 	}
 
-	return nil
-}
+	outcome := OutcomeStandard
+	if scripted {
+		var err error
+		outcome, err = variant.Outcome(g, pos)
+		if err != nil {
+			g.unplace(pos)
+			return err
+		}
+	}
 
-func checkWin(g *Game, pos Position, cell Cell) bool {
-	window := 1
-	delta := 1
+	line := checkWin(g, pos, cell)
+	if outcome == OutcomeContinue {
+		line = nil
+	}
+	if line != nil {
+		g.WinLines = append(g.WinLines, line)
+	}
 
-	// check vertical
-	for window < g.WinRow {
-		if pos.Y+delta >= 0 && pos.Y+delta < g.Height() && // out of bounds
-			cell == g.Board[pos.Y+delta][pos.X] { // equality
-			window++
+	switch outcome {
+	case OutcomeWin:
+		g.Status = StatusWin
+		g.Winner = player
+		return nil
+	case OutcomeLoss:
+		g.lose(player)
+		return nil
+	case OutcomeDraw:
+		g.Status = StatusDraw
+		return nil
+	}
 
-			if delta > 0 {
-				delta++
-			} else {
-				delta--
+	switch g.Rules {
+	case RulesOrderChaos:
+		// the line counts for Order, no matter who completed it
+		if line != nil {
+			g.Status = StatusWin
+			g.Winner = RoleOrder
+			return nil
+		}
+	case RulesMisere:
+		if line != nil {
+			g.lose(player)
+			return nil
+		}
+	case RulesNotakto:
+		if line != nil && g.deadLayers() == g.Layers() {
+			g.lose(player)
+			return nil
+		}
+	default:
+		if line != nil || g.captureWin(player) {
+			g.Ranking = append(g.Ranking, player)
+
+			active := g.Players - len(g.Ranking)
+			if g.Scoring != ScoringElimination || active == 1 {
+				if active == 1 {
+					g.Ranking = append(g.Ranking, g.nextPlayer(player))
+				}
+
+				g.Status = StatusWin
+				g.Winner = g.Ranking[0]
+				return nil
 			}
 
-		} else if delta > 0 { // change direction
-			delta = -1
-		} else { // no win
-			delta = 1
-			window = 1
-			break
+			// the turn passes on even if stones are left
+			g.StonesLeft = 0
 		}
 	}
-	if window == g.WinRow {
-		return true
+
+	// check draw condition: the board is full or no side can complete a
+	// line any more, an infinite board never fills up
+	if !g.Infinite && (len(g.Board) == g.cellCount() || g.linesClosed()) {
+		if g.Rules == RulesOrderChaos {
+			g.Status = StatusWin
+			g.Winner = RoleChaos
+			return nil
+		}
+
+		if len(g.Ranking) > 0 {
+			g.Status = StatusWin
+			g.Winner = g.Ranking[0]
+			return nil
+		}
+
+		g.Status = StatusDraw
+		return nil
+	}
+
+	// the same side keeps moving until the turn is complete
+	if g.StonesLeft == 0 {
+		g.StonesLeft = g.turnStones(len(g.History))
+		g.Turn = g.nextPlayer(player)
 	}
 
-	// // check horizontal
-	for window < g.WinRow {
-		if pos.X+delta >= 0 && pos.X+delta < g.Width() && // out of bounds
-			cell == g.Board[pos.Y][pos.X+delta] { // equality
-			window++
-			if delta > 0 {
-				delta++
-			} else {
-				delta--
-			}
-		} else if delta > 0 { // change direction
-			delta = -1
-		} else { // no win
-			delta = 1
-			window = 1
-			break
+	// a variant may leave nowhere to play before the board is full
+	if scripted && len(LegalMoves(g)) == 0 {
+		g.Status = StatusDraw
+	}
+
+	return nil
+}
+
+// checkWin returns the line of equal cells through pos if it is at least
+// WinRow long, or nil.
+func checkWin(g *Game, pos Position, cell Cell) []Position {
+	for _, d := range g.directions() {
+		line := g.lineThrough(pos, d, cell)
+		if len(line) == g.WinRow || (len(line) > g.WinRow && !g.Rules.Exact()) {
+			return line
 		}
 	}
-	if window == g.WinRow {
-		return true
-	}
-
-	// // check diagonal-top-left-bottom-right
-	// for window < g.InARowToWin {
-	// 	if pos.x+delta >= 0 && pos.x+delta < len(g.board[0]) &&
-	// 		pos.y+delta >= 0 && pos.y+delta < len(g.board[0]) &&
-	// 		cell == g.board[pos.y+delta][pos.x+delta] { // check out of bounds and equality
-	// 		window++
-	// 		delta++
-	// 	} else if delta > 0 { // change direction
-	// 		delta = -1
-	// 	} else { // no win
-	// 		delta = 1
-	// 		window = 1
-	// 		break
-	// 	}
-	// }
-	// if window == g.InARowToWin {
-	// 	return true
-	// }
-
-	// // check diagonal-top-right-bottom-left
-	// for window < g.InARowToWin {
-	// 	if pos.x+delta >= 0 && pos.x+delta < len(g.board[0]) &&
-	// 		pos.y-delta >= 0 && pos.y-delta < len(g.board[0]) &&
-	// 		cell == g.board[pos.y-delta][pos.x+delta] { // check out of bounds and equality
-	// 		window++
-	// 		delta++
-	// 	} else if delta > 0 { // change direction
-	// 		delta = -1
-	// 	} else { // no win
-	// 		delta = 1
-	// 		window = 1
-	// 		break
-	// 	}
-	// }
-
-	return window == g.WinRow
+
+	return nil
 }
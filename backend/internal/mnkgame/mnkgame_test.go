@@ -9,7 +9,8 @@ import (
 
 func TestNewGame(t *testing.T) {
 	game := NewGame(15, 15, 5)
-	assert.Equal(t, StatusTurnX, game.Status)
+	assert.Equal(t, StatusTurn, game.Status)
+	assert.Equal(t, PlayerX, game.Turn)
 }
 
 func TestVerticalWin(t *testing.T) {
@@ -20,7 +21,8 @@ func TestVerticalWin(t *testing.T) {
 	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 1}))
 	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 1}))
 	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 2}))
-	require.Equal(t, StatusWinX.String(), g.Status.String())
+	require.Equal(t, StatusWin, g.Status)
+	require.Equal(t, PlayerX, g.Winner)
 }
 
 func TestHorizontalWin(t *testing.T) {
@@ -33,25 +35,207 @@ func TestHorizontalWin(t *testing.T) {
 	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2}))
 	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 1}))
 
-	require.Equal(t, StatusWinO.String(), g.Status.String())
-}
-
-// func TestDiagonalTopLeftBottomRightWin(t *testing.T) {
-// 	game := NewGame(5, 5, 3)
-// 	MakeTurn(game, Position{x: 1, y: 1})
-// 	MakeTurn(game, Position{x: 2, y: 0})
-// 	MakeTurn(game, Position{x: 0, y: 0})
-// 	MakeTurn(game, Position{x: 1, y: 0})
-// 	MakeTurn(game, Position{x: 2, y: 2})
-// 	assert.Equal(t, StatusWinX, game.Status)
-// }
-
-// func TestDiagonalTopRightBottomLeftWin(t *testing.T) {
-// 	game := NewGame(5, 5, 3)
-// 	MakeTurn(game, Position{x: 1, y: 1})
-// 	MakeTurn(game, Position{x: 0, y: 0})
-// 	MakeTurn(game, Position{x: 2, y: 0})
-// 	MakeTurn(game, Position{x: 1, y: 0})
-// 	MakeTurn(game, Position{x: 0, y: 2})
-// 	assert.Equal(t, StatusWinX, game.Status)
-// }
+	require.Equal(t, StatusWin, g.Status)
+	require.Equal(t, PlayerO, g.Winner)
+}
+
+func TestThreePlayerRotation(t *testing.T) {
+	g := NewMultiplayerGame(6, 6, 3, 3, ScoringFirstToK)
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	assert.Equal(t, PlayerO, g.Turn)
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 1}))
+	assert.Equal(t, PlayerTriangle, g.Turn)
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 2}))
+	assert.Equal(t, PlayerX, g.Turn)
+
+	assert.Equal(t, CellTriangle, g.Board[Position{X: 0, Y: 2}])
+	assert.Equal(t, PlayerTriangle, g.History[2].Player)
+}
+
+func TestFirstToKScoring(t *testing.T) {
+	g := NewMultiplayerGame(6, 6, 3, 3, ScoringFirstToK)
+
+	for x := range 3 {
+		require.Nil(t, MakeTurn(g, Position{X: x, Y: 0}))
+		if x < 2 {
+			require.Nil(t, MakeTurn(g, Position{X: x, Y: 1}))
+			require.Nil(t, MakeTurn(g, Position{X: x, Y: 2}))
+		}
+	}
+
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+	assert.Equal(t, "Win X", g.StatusText())
+}
+
+func TestEliminationScoring(t *testing.T) {
+	g := NewMultiplayerGame(6, 6, 3, 3, ScoringElimination)
+
+	// X completes the top row first and leaves the rotation
+	for x := range 3 {
+		require.Nil(t, MakeTurn(g, Position{X: x, Y: 0}))
+		if x < 2 {
+			require.Nil(t, MakeTurn(g, Position{X: x, Y: 1}))
+			require.Nil(t, MakeTurn(g, Position{X: x, Y: 2}))
+		}
+	}
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Equal(t, []Player{PlayerX}, g.Ranking)
+	assert.Equal(t, PlayerO, g.Turn)
+
+	// O and △ continue, O completes the second row
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 1}))
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+	assert.Equal(t, []Player{PlayerX, PlayerO, PlayerTriangle}, g.Ranking)
+}
+
+func TestBecomeOpponentFillsSeats(t *testing.T) {
+	g := CreateGame(CreateGameParams{
+		PlayerID: "a", Players: 3, Width: 5, Height: 5, WinRow: 4,
+	})
+	assert.Equal(t, StatusOpponent, g.Status)
+
+	require.Nil(t, BecomeOpponent(g, "b"))
+	assert.NotNil(t, BecomeOpponent(g, "b"))
+	assert.Equal(t, StatusOpponent, g.Status)
+
+	require.Nil(t, BecomeOpponent(g, "c"))
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Equal(t, PlayerX, g.Turn)
+
+	seat, ok := g.Seat("c")
+	require.True(t, ok)
+	assert.Equal(t, PlayerTriangle, seat)
+	assert.Equal(t, PlayerID("b"), g.PlayerID(PlayerO))
+}
+
+func TestHostGameKeepsSideToMove(t *testing.T) {
+	g := NewGame(3, 3, 3)
+	g.Board[Position{X: 1, Y: 1}] = CellX
+	g.Turn = PlayerO
+
+	g = HostGame(g, "a", false)
+	assert.Equal(t, StatusOpponent, g.Status)
+	require.Nil(t, BecomeOpponent(g, "b"))
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Equal(t, PlayerO, g.Turn)
+
+	found, ok := FindGame(g.ID)
+	assert.True(t, ok)
+	assert.Same(t, g, found)
+}
+
+func TestDiagonalTopLeftBottomRightWin(t *testing.T) {
+	g := NewGame(5, 5, 3)
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2}))
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+}
+
+func TestDiagonalTopRightBottomLeftWin(t *testing.T) {
+	g := NewGame(5, 5, 3)
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 2}))
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+}
+
+func TestConnect6TurnStructure(t *testing.T) {
+	g := NewConnectGame(19, 19, 6, 2, 1)
+	assert.Equal(t, 1, g.StonesLeft)
+
+	require.Nil(t, MakeTurn(g, Position{X: 9, Y: 9}))
+	assert.Equal(t, PlayerO, g.Turn)
+	assert.Equal(t, 2, g.StonesLeft)
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	assert.Equal(t, PlayerO, g.Turn)
+	assert.Equal(t, 1, g.StonesLeft)
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	assert.Equal(t, PlayerX, g.Turn)
+	assert.Equal(t, 2, g.StonesLeft)
+
+	require.Len(t, g.History, 2)
+	assert.Equal(t, []Position{{X: 9, Y: 9}}, g.History[0].Stones)
+	assert.Equal(t, []Position{{X: 0, Y: 0}, {X: 1, Y: 0}}, g.History[1].Stones)
+}
+
+func TestConnectWinMidTurn(t *testing.T) {
+	g := NewConnectGame(5, 5, 3, 2, 1)
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 4, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 4, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 4}))
+	require.Nil(t, MakeTurn(g, Position{X: 4, Y: 3}))
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 3}))
+	// the first stone of X's turn completes the column
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 2}))
+
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+	assert.Equal(t, 1, g.StonesLeft)
+	assert.NotNil(t, MakeTurn(g, Position{X: 2, Y: 2}))
+}
+
+func TestInfiniteBoard(t *testing.T) {
+	g := NewInfiniteGame(4)
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 10, Y: -10}))
+	require.Nil(t, MakeTurn(g, Position{X: -1, Y: -1}))
+	require.Nil(t, MakeTurn(g, Position{X: 10, Y: -9}))
+	require.Nil(t, MakeTurn(g, Position{X: -2, Y: -2}))
+	require.Nil(t, MakeTurn(g, Position{X: 10, Y: -8}))
+	assert.NotNil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: -3, Y: -3}))
+
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+	assert.Len(t, g.Board, 7)
+}
+
+func TestInfiniteBoardReach(t *testing.T) {
+	g := NewInfiniteGame(5)
+	assert.False(t, g.InBounds(Position{X: InfiniteReach + 1}), "an empty board is reached from the origin")
+
+	require.Nil(t, MakeTurn(g, Position{X: InfiniteReach, Y: -InfiniteReach}))
+	require.Nil(t, MakeTurn(g, Position{X: 2 * InfiniteReach, Y: 0}))
+	assert.NotNil(t, MakeTurn(g, Position{X: 1_000_000_000, Y: 0}))
+	assert.NotNil(t, MakeTurn(g, Position{X: 0, Y: 0, Z: 1}))
+	assert.Len(t, g.Board, 2)
+}
+
+func TestViewport(t *testing.T) {
+	g := NewInfiniteGame(5)
+
+	lo, hi := g.Viewport(2)
+	assert.Equal(t, Position{X: -2, Y: -2}, lo)
+	assert.Equal(t, Position{X: 2, Y: 2}, hi)
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 4, Y: -3}))
+	lo, hi = g.Viewport(2)
+	assert.Equal(t, Position{X: -2, Y: -5}, lo)
+	assert.Equal(t, Position{X: 6, Y: 2}, hi)
+
+	b := NewGame(3, 4, 3)
+	lo, hi = b.Viewport(2)
+	assert.Equal(t, Position{}, lo)
+	assert.Equal(t, Position{X: 2, Y: 3}, hi)
+}
+
+func TestPositionString(t *testing.T) {
+	assert.Equal(t, "(-3,5)", Position{X: -3, Y: 5}.String())
+}
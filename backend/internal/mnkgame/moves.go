@@ -0,0 +1,141 @@
+package mnkgame
+
+import (
+	"errors"
+	"maps"
+	"slices"
+)
+
+var ErrNoTurns = errors.New("There is no stone to take back")
+
+// Clone returns a deep copy of the game that can be played on without
+// touching the original, e.g. by a search.
+func (g *Game) Clone() *Game {
+	c := *g
+	c.Seats = slices.Clone(g.Seats)
+	c.Board = maps.Clone(g.Board)
+	c.Ranking = slices.Clone(g.Ranking)
+	c.WinLines = slices.Clone(g.WinLines)
+	c.Captures = maps.Clone(g.Captures)
+	c.Blocked = maps.Clone(g.Blocked)
+	c.Probes = maps.Clone(g.Probes)
+	for p, probes := range c.Probes {
+		c.Probes[p] = slices.Clone(probes)
+	}
+
+	c.History = make([]Turn, len(g.History))
+	for i, turn := range g.History {
+		c.History[i] = Turn{
+			Player:   turn.Player,
+			Stones:   slices.Clone(turn.Stones),
+			Captured: slices.Clone(turn.Captured),
+		}
+	}
+
+	return &c
+}
+
+// LegalMoves returns the positions the side to move may play. On an
+// infinite board only the cells next to the stones are returned.
+func LegalMoves(g *Game) []Position {
+	if g.Status != StatusTurn {
+		return nil
+	}
+
+	var moves []Position
+	variant, scripted := g.variant()
+
+	lo, hi := g.Viewport(1)
+	for z := range g.Layers() {
+		if g.DeadLayer(z) {
+			continue
+		}
+
+		for y := lo.Y; y <= hi.Y; y++ {
+			for x := lo.X; x <= hi.X; x++ {
+				pos := Position{X: x, Y: y, Z: z}
+				if !g.InBounds(pos) || g.Board[pos] != CellEmpty {
+					continue
+				}
+				// a broken rule keeps the cell, so trying it reports the error
+				if scripted {
+					if ok, err := variant.Legal(g, pos); err == nil && !ok {
+						continue
+					}
+				}
+
+				moves = append(moves, pos)
+			}
+		}
+	}
+
+	return moves
+}
+
+// UndoTurn takes back the last stone placed, returning the game to the
+// position before it, so a search can play and unplay moves on one game.
+// What a side learned by probing in Phantom is kept.
+func UndoTurn(g *Game) error {
+	if len(g.History) == 0 {
+		return ErrNoTurns
+	}
+
+	index := len(g.History) - 1
+	turn := &g.History[index]
+	player := turn.Player
+	pos := turn.Stones[len(turn.Stones)-1]
+
+	// the captures of the stone are the last pairs of the turn lined up
+	// behind it
+	for len(turn.Captured) >= 2 {
+		first := turn.Captured[len(turn.Captured)-2]
+		second := turn.Captured[len(turn.Captured)-1]
+		if !g.capturedBy(pos, first.Position, second.Position) {
+			break
+		}
+
+		g.put(first.Position, first.Cell)
+		g.put(second.Position, second.Cell)
+		g.Captures[player]--
+		turn.Captured = turn.Captured[:len(turn.Captured)-2]
+	}
+
+	g.put(pos, CellEmpty)
+	turn.Stones = turn.Stones[:len(turn.Stones)-1]
+
+	// only a line through the stone can have been completed by it
+	for len(g.WinLines) > 0 && slices.Contains(g.WinLines[len(g.WinLines)-1], pos) {
+		g.WinLines = g.WinLines[:len(g.WinLines)-1]
+	}
+	if i := slices.Index(g.Ranking, player); i >= 0 {
+		g.Ranking = g.Ranking[:i]
+	}
+
+	g.Status = StatusTurn
+	g.Winner = 0
+	g.Loser = 0
+	g.Turn = player
+	g.StonesLeft = g.turnStones(index) - len(turn.Stones)
+	if len(turn.Stones) == 0 {
+		g.History = g.History[:index]
+	}
+
+	return nil
+}
+
+// capturedBy reports whether a stone at pos flanks the pair first, second.
+func (g *Game) capturedBy(pos, first, second Position) bool {
+	for _, d := range g.directions() {
+		for _, dir := range []Position{d, {X: -d.X, Y: -d.Y, Z: -d.Z}} {
+			next, ok := g.step(pos, dir)
+			if !ok || next != first {
+				continue
+			}
+			if next, ok = g.step(next, dir); ok && next == second {
+				return true
+			}
+		}
+	}
+
+	return false
+}
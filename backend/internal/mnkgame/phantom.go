@@ -0,0 +1,76 @@
+package mnkgame
+
+import "slices"
+
+// NewPhantomGame creates a two-player Phantom game.
+func NewPhantomGame(width, height, winRow int) *Game {
+	game := NewGame(width, height, winRow)
+	game.Rules = RulesPhantom
+
+	return game
+}
+
+func (g *Game) probe(p Player, pos Position) {
+	if g.Probes == nil {
+		g.Probes = map[Player][]Position{}
+	}
+
+	if !slices.Contains(g.Probes[p], pos) {
+		g.Probes[p] = append(g.Probes[p], pos)
+	}
+}
+
+// View returns the game as the given player may see it. Only Phantom games
+// hide anything: while they are in progress a side sees its own stones and
+// the cells it probed, and spectators see no stones at all.
+func (g *Game) View(id PlayerID) *Game {
+	if g.Rules != RulesPhantom || g.Status != StatusTurn {
+		return g
+	}
+
+	p, _ := g.Seat(id)
+	return g.ViewAs(p)
+}
+
+// ViewAs returns the projection of a Phantom game for side p, or for a
+// spectator if p is not a side. The returned game must not be played on.
+func (g *Game) ViewAs(p Player) *Game {
+	if g.Rules != RulesPhantom || g.Status != StatusTurn {
+		return g
+	}
+
+	v := g.Clone()
+	history := v.History
+	v.Board = Board{}
+	v.Probes = map[Player][]Position{}
+	v.History = nil
+	v.WinLines = nil
+
+	for pos, cell := range g.Board {
+		if cell == p.Cell() {
+			v.Board[pos] = cell
+		}
+	}
+
+	for _, pos := range g.Probes[p] {
+		v.Board[pos] = g.Board[pos]
+		v.Probes[p] = append(v.Probes[p], pos)
+	}
+
+	v.Hash = v.Board.Hash()
+
+	// the turn order is public, the stones of the other sides are not
+	for _, turn := range history {
+		if turn.Player != p {
+			turn = Turn{Player: turn.Player}
+		}
+		v.History = append(v.History, turn)
+	}
+
+	return v
+}
+
+// Probed reports whether side p found pos occupied by trying to play it.
+func (g *Game) Probed(p Player, pos Position) bool {
+	return slices.Contains(g.Probes[p], pos)
+}
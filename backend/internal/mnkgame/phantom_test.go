@@ -0,0 +1,91 @@
+package mnkgame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSeatedPhantomGame() *Game {
+	g := NewPhantomGame(3, 3, 3)
+	g.Seats = []PlayerID{"x", "o"}
+
+	return g
+}
+
+func TestPhantomProbeKeepsTurn(t *testing.T) {
+	g := newSeatedPhantomGame()
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	assert.ErrorIs(t, MakeTurn(g, Position{X: 1, Y: 1}), ErrOccupied)
+
+	assert.Equal(t, PlayerO, g.Turn)
+	assert.True(t, g.Probed(PlayerO, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	assert.Equal(t, PlayerX, g.Turn)
+}
+
+func TestPhantomOnlyHiddenStonesAreProbed(t *testing.T) {
+	g := newSeatedPhantomGame()
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	assert.ErrorIs(t, MakeTurn(g, Position{X: 1, Y: 1}), ErrProbed)
+	assert.ErrorIs(t, MakeTurn(g, Position{X: 5, Y: 5}), ErrOutOfBounds)
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+
+	// X sees its own stone, trying it again is a plain mistake
+	err := MakeTurn(g, Position{X: 1, Y: 1})
+	assert.ErrorIs(t, err, ErrOccupied)
+	assert.NotErrorIs(t, err, ErrProbed)
+	assert.False(t, g.Probed(PlayerX, Position{X: 1, Y: 1}))
+
+	g.Status = StatusDraw
+	err = MakeTurn(g, Position{X: 0, Y: 0})
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, ErrOccupied, "a finished game is checked first")
+}
+
+func TestPhantomViewHidesOpponentStones(t *testing.T) {
+	g := newSeatedPhantomGame()
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2}))
+	assert.ErrorIs(t, MakeTurn(g, Position{X: 1, Y: 1}), ErrOccupied)
+
+	x := g.View("x")
+	assert.Equal(t, Board{{X: 1, Y: 1}: CellX, {X: 2, Y: 2}: CellX}, x.Board)
+	assert.Empty(t, x.History[1].Stones)
+	assert.Empty(t, x.Probes[PlayerO])
+
+	o := g.View("o")
+	assert.Equal(t, Board{{X: 0, Y: 0}: CellO, {X: 1, Y: 1}: CellX}, o.Board)
+	assert.Empty(t, o.History[0].Stones)
+	assert.Equal(t, []Position{{X: 0, Y: 0}}, o.History[1].Stones)
+
+	spectator := g.View("someone")
+	assert.Empty(t, spectator.Board)
+
+	// the projection does not touch the game
+	assert.Len(t, g.Board, 3)
+}
+
+func TestPhantomRevealsEverythingAtTheEnd(t *testing.T) {
+	g := newSeatedPhantomGame()
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Same(t, g, g.View("o"))
+}
+
+func TestViewOfOpenGame(t *testing.T) {
+	g := NewGame(3, 3, 3)
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	assert.Same(t, g, g.View("anyone"))
+}
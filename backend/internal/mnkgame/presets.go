@@ -0,0 +1,38 @@
+package mnkgame
+
+// Preset is a well-known rule set that fills in the create game form.
+type Preset struct {
+	Name   string
+	Params CreateGameParams
+}
+
+var Presets = []Preset{
+	{
+		Name:   "Tic-tac-toe",
+		Params: CreateGameParams{Width: 3, Height: 3, WinRow: 3},
+	},
+	{
+		Name:   "Gomoku",
+		Params: CreateGameParams{Width: 15, Height: 15, WinRow: 5},
+	},
+	{
+		Name:   "Connect6",
+		Params: CreateGameParams{Width: 19, Height: 19, WinRow: 6, StonesPerTurn: 2},
+	},
+	{
+		Name:   "Pente",
+		Params: CreateGameParams{Width: 19, Height: 19, WinRow: 5, Rules: RulesPente},
+	},
+	{
+		Name:   "Order and Chaos",
+		Params: CreateGameParams{Width: 6, Height: 6, WinRow: 5, Rules: RulesOrderChaos},
+	},
+	{
+		Name:   "Notakto (3 boards)",
+		Params: CreateGameParams{Width: 3, Height: 3, WinRow: 3, Rules: RulesNotakto, Boards: 3},
+	},
+	{
+		Name:   "Qubic (4x4x4)",
+		Params: CreateGameParams{Width: 4, Height: 4, WinRow: 4, Geometry: GeometryCube},
+	},
+}
@@ -0,0 +1,181 @@
+package mnkgame
+
+// Rules selects the rule set played on top of the m,n,k board.
+type Rules int
+
+const (
+	RulesStandard Rules = iota
+	// RulesPente removes pairs of enemy stones flanked by the stone just
+	// placed. A side wins with WinRow in a row or with CaptureWin pairs.
+	RulesPente
+	// RulesMisere is played by two sides, completing a line loses.
+	RulesMisere
+	// RulesNotakto is played by two sides on Depth boards where both place
+	// X. A line kills its board, whoever kills the last board loses.
+	RulesNotakto
+	// RulesOrderChaos is played by two sides that both may place X or O.
+	// Order (the first seat) wins with exactly WinRow equal marks in a row,
+	// Chaos (the second seat) wins when the board fills up without one.
+	RulesOrderChaos
+	// RulesPhantom hides the stones of the other sides. A side that tries
+	// an occupied cell learns its content and has to try again.
+	RulesPhantom
+)
+
+// Roles of the two sides in Order and Chaos.
+const (
+	RoleOrder = PlayerX
+	RoleChaos = PlayerO
+)
+
+var rulesName = map[Rules]string{
+	RulesStandard: "Standard",
+	RulesPente:    "Pente",
+	RulesMisere:   "Misère",
+	RulesNotakto:  "Notakto",
+
+	RulesOrderChaos: "Order and Chaos",
+	RulesPhantom:    "Phantom",
+}
+
+// TwoSided reports whether the rule set only works for two sides.
+func (r Rules) TwoSided() bool {
+	return r == RulesMisere || r == RulesNotakto || r == RulesOrderChaos
+}
+
+// Exact reports whether lines longer than WinRow do not count.
+func (r Rules) Exact() bool {
+	return r == RulesOrderChaos
+}
+
+// ChooseMark reports whether the mover picks the mark to place.
+func (r Rules) ChooseMark() bool {
+	return r == RulesOrderChaos
+}
+
+// SideName returns the name of a side, which is its role where the rules
+// give the sides different goals and its mark otherwise.
+func (g *Game) SideName(p Player) string {
+	if g.Rules == RulesOrderChaos {
+		switch p {
+		case RoleOrder:
+			return "Order"
+		case RoleChaos:
+			return "Chaos"
+		}
+	}
+
+	return p.String()
+}
+
+// NewOrderChaosGame creates an Order and Chaos game on the classic 6x6 board.
+func NewOrderChaosGame() *Game {
+	game := NewGame(6, 6, 5)
+	game.Rules = RulesOrderChaos
+
+	return game
+}
+
+func (r Rules) String() string {
+	return rulesName[r]
+}
+
+const DefaultCaptureWin = 5
+
+// NewPenteGame creates a two-player Pente game.
+func NewPenteGame(width, height int) *Game {
+	game := NewGame(width, height, 5)
+	game.Rules = RulesPente
+
+	return game
+}
+
+// capture removes the pairs of enemy stones that the stone at pos flanks
+// together with another stone of the same cell, and returns them.
+func capture(g *Game, pos Position, cell Cell) []Stone {
+	var captured []Stone
+
+	for _, d := range g.directions() {
+		for _, dir := range []Position{d, {X: -d.X, Y: -d.Y, Z: -d.Z}} {
+			first, ok := g.step(pos, dir)
+			if !ok {
+				continue
+			}
+			second, ok := g.step(first, dir)
+			if !ok {
+				continue
+			}
+			end, ok := g.step(second, dir)
+			if !ok {
+				continue
+			}
+
+			enemy := g.Board[first]
+			if enemy == CellEmpty || enemy == cell || g.Board[second] != enemy || g.Board[end] != cell {
+				continue
+			}
+
+			captured = append(captured, Stone{first, enemy}, Stone{second, enemy})
+			g.put(first, CellEmpty)
+			g.put(second, CellEmpty)
+		}
+	}
+
+	return captured
+}
+
+func (g *Game) captureWin(p Player) bool {
+	return g.Rules == RulesPente && g.Captures[p] >= g.CaptureWin
+}
+
+// NewNotaktoGame creates a Notakto game on the given number of 3x3 boards.
+func NewNotaktoGame(boards int) *Game {
+	game := NewGame(3, 3, 3)
+	game.Rules = RulesNotakto
+	game.Depth = boards
+
+	return game
+}
+
+// mark returns the cell placed by the given side.
+func (g *Game) mark(p Player) Cell {
+	if g.Rules == RulesNotakto {
+		return CellX
+	}
+
+	return p.Cell()
+}
+
+// deadLayer reports whether a Notakto board already has a line on it.
+func (g *Game) deadLayer(z int) bool {
+	for _, line := range g.WinLines {
+		if line[0].Z == z {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Game) deadLayers() int {
+	count := 0
+	for z := range g.Layers() {
+		if g.deadLayer(z) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// DeadLayer reports whether no more stones can be placed on layer z.
+func (g *Game) DeadLayer(z int) bool {
+	return g.Rules == RulesNotakto && g.deadLayer(z)
+}
+
+// lose ends a two-sided game with p losing.
+func (g *Game) lose(p Player) {
+	g.Status = StatusLoss
+	g.Loser = p
+	g.Winner = g.nextPlayer(p)
+}
@@ -0,0 +1,229 @@
+package mnkgame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPenteCapture(t *testing.T) {
+	g := NewPenteGame(9, 9)
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 8, Y: 8}))
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 4, Y: 1}))
+
+	assert.Equal(t, CellEmpty, g.Board[Position{X: 2, Y: 1}])
+	assert.Equal(t, CellEmpty, g.Board[Position{X: 3, Y: 1}])
+	assert.Equal(t, 1, g.Captures[PlayerX])
+	assert.Equal(t, []Stone{
+		{Position{X: 3, Y: 1}, CellO},
+		{Position{X: 2, Y: 1}, CellO},
+	}, g.History[4].Captured)
+
+	// captured cells can be played again
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 1}))
+}
+
+func TestPenteNoCaptureOfSingleOrTriple(t *testing.T) {
+	g := NewPenteGame(9, 9)
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+	assert.Equal(t, CellO, g.Board[Position{X: 1, Y: 0}])
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 5}))
+	require.Nil(t, MakeTurn(g, Position{X: 8, Y: 8}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 5}))
+	require.Nil(t, MakeTurn(g, Position{X: 8, Y: 7}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 5}))
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 5}))
+	assert.Equal(t, 0, g.Captures[PlayerO])
+}
+
+func TestPenteCaptureWin(t *testing.T) {
+	g := NewPenteGame(19, 19)
+	g.Captures[PlayerX] = 4
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 10, Y: 10}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 3}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 4}))
+
+	assert.Equal(t, 5, g.Captures[PlayerX])
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+	assert.Empty(t, g.WinLines)
+}
+
+func TestPenteFiveInARowWin(t *testing.T) {
+	g := NewPenteGame(19, 19)
+
+	for x := range 4 {
+		require.Nil(t, MakeTurn(g, Position{X: x, Y: 0}))
+		require.Nil(t, MakeTurn(g, Position{X: x, Y: 10}))
+	}
+	require.Nil(t, MakeTurn(g, Position{X: 4, Y: 0}))
+
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+}
+
+func TestStandardRulesDoNotCapture(t *testing.T) {
+	g := NewGame(9, 9, 5)
+
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 8, Y: 8}))
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 4, Y: 1}))
+
+	assert.Equal(t, CellO, g.Board[Position{X: 2, Y: 1}])
+}
+
+func TestMisereLineLoses(t *testing.T) {
+	g := NewGame(3, 3, 3)
+	g.Rules = RulesMisere
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+
+	assert.Equal(t, StatusLoss, g.Status)
+	assert.Equal(t, PlayerX, g.Loser)
+	assert.Equal(t, PlayerO, g.Winner)
+	assert.Equal(t, "Loss X, win O", g.StatusText())
+}
+
+func TestNotaktoKillsBoards(t *testing.T) {
+	g := NewNotaktoGame(2)
+
+	// both sides place X, the third X in the top row kills board 1
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	assert.Equal(t, CellX, g.Board[Position{X: 1, Y: 0}])
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.True(t, g.DeadLayer(0))
+	assert.False(t, g.DeadLayer(1))
+	assert.NotNil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+
+	// killing the last board loses
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0, Z: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1, Z: 1}))
+	assert.Equal(t, PlayerO, g.Turn)
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2, Z: 1}))
+
+	assert.Equal(t, StatusLoss, g.Status)
+	assert.Equal(t, PlayerO, g.Loser)
+	assert.Equal(t, PlayerX, g.Winner)
+}
+
+func TestNotaktoLegalMovesSkipDeadBoards(t *testing.T) {
+	g := NewNotaktoGame(2)
+	for x := range 3 {
+		require.Nil(t, MakeTurn(g, Position{X: x, Y: 0}))
+	}
+
+	moves := LegalMoves(g)
+	assert.Len(t, moves, 9)
+	for _, m := range moves {
+		assert.Equal(t, 1, m.Z)
+	}
+}
+
+func TestCreateGameWithBot(t *testing.T) {
+	g := CreateGame(CreateGameParams{
+		PlayerID: "a", Players: 3, Rules: RulesMisere, Bot: true, Width: 3, Height: 3, WinRow: 3,
+	})
+
+	assert.Equal(t, 2, g.Players)
+	assert.Equal(t, []PlayerID{"a", BotID}, g.Seats)
+	assert.Equal(t, StatusTurn, g.Status)
+}
+
+func TestClone(t *testing.T) {
+	g := NewPenteGame(9, 9)
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+
+	c := g.Clone()
+	require.Nil(t, MakeTurn(c, Position{X: 2, Y: 2}))
+	require.Nil(t, MakeTurn(c, Position{X: 3, Y: 3}))
+
+	assert.Len(t, g.Board, 1)
+	assert.Len(t, g.History, 1)
+	assert.Equal(t, PlayerO, g.Turn)
+	assert.Len(t, c.Board, 3)
+}
+
+func TestOrderChaosChooseMark(t *testing.T) {
+	g := NewOrderChaosGame()
+
+	require.Nil(t, MakeMarkedTurn(g, Position{X: 0, Y: 0}, CellO))
+	require.Nil(t, MakeMarkedTurn(g, Position{X: 1, Y: 0}, CellO))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+	assert.NotNil(t, MakeMarkedTurn(g, Position{X: 3, Y: 0}, CellTriangle))
+
+	assert.Equal(t, CellO, g.Board[Position{X: 0, Y: 0}])
+	assert.Equal(t, CellO, g.Board[Position{X: 1, Y: 0}])
+	assert.Equal(t, CellX, g.Board[Position{X: 2, Y: 0}])
+	assert.Equal(t, "Turn Chaos", g.StatusText())
+}
+
+func TestOtherRulesRejectForeignMark(t *testing.T) {
+	g := NewGame(3, 3, 3)
+	assert.NotNil(t, MakeMarkedTurn(g, Position{X: 0, Y: 0}, CellO))
+	assert.Nil(t, MakeMarkedTurn(g, Position{X: 0, Y: 0}, CellX))
+}
+
+func TestOrderWinsWhenChaosCompletesLine(t *testing.T) {
+	g := NewOrderChaosGame()
+
+	require.Nil(t, MakeMarkedTurn(g, Position{X: 0, Y: 5}, CellX))
+	for x := range 4 {
+		require.Nil(t, MakeMarkedTurn(g, Position{X: x, Y: 2}, CellO))
+	}
+	assert.Equal(t, RoleChaos, g.Turn)
+	require.Nil(t, MakeMarkedTurn(g, Position{X: 4, Y: 2}, CellO))
+
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, RoleOrder, g.Winner)
+	assert.Equal(t, "Win Order", g.StatusText())
+}
+
+func TestOrderChaosLineOfSixDoesNotCount(t *testing.T) {
+	g := NewOrderChaosGame()
+
+	for _, x := range []int{0, 1, 2, 4, 5} {
+		require.Nil(t, MakeMarkedTurn(g, Position{X: x, Y: 0}, CellX))
+	}
+	require.Nil(t, MakeMarkedTurn(g, Position{X: 3, Y: 0}, CellX))
+
+	assert.Equal(t, StatusTurn, g.Status)
+}
+
+func TestChaosWinsFullBoard(t *testing.T) {
+	g := NewOrderChaosGame()
+
+	// alternate marks in pairs so no row, column or diagonal has five equal
+	for y := range 6 {
+		for x := range 6 {
+			mark := CellX
+			if (x/2+y)%2 == 1 {
+				mark = CellO
+			}
+			require.Nil(t, MakeMarkedTurn(g, Position{X: x, Y: y}, mark))
+		}
+	}
+
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, RoleChaos, g.Winner)
+}
@@ -0,0 +1,170 @@
+package mnkgame
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// Topology decides which board edges are joined together. Stones are always
+// placed inside the board, but lines may run across joined edges.
+type Topology int
+
+const (
+	TopologyFlat Topology = iota
+	TopologyWrapX
+	TopologyWrapY
+	TopologyTorus
+)
+
+var topologyName = map[Topology]string{
+	TopologyFlat:  "Flat",
+	TopologyWrapX: "Cylinder (wraps horizontally)",
+	TopologyWrapY: "Cylinder (wraps vertically)",
+	TopologyTorus: "Torus (wraps both ways)",
+}
+
+func (t Topology) String() string {
+	return topologyName[t]
+}
+
+func (t Topology) WrapsX() bool {
+	return t == TopologyWrapX || t == TopologyTorus
+}
+
+func (t Topology) WrapsY() bool {
+	return t == TopologyWrapY || t == TopologyTorus
+}
+
+// squareDirections holds one vector per line direction on a square grid:
+// horizontal, vertical and both diagonals.
+var squareDirections = []Position{
+	{X: 1, Y: 0},
+	{X: 0, Y: 1},
+	{X: 1, Y: 1},
+	{X: 1, Y: -1},
+}
+
+// step moves pos by d, wrapping around the joined edges. It reports false if
+// the move leaves the board.
+func (g *Game) step(pos Position, d Position) (Position, bool) {
+	next := Position{X: pos.X + d.X, Y: pos.Y + d.Y, Z: pos.Z + d.Z}
+
+	if g.Topology.WrapsX() {
+		next.X = (next.X + g.Width) % g.Width
+	}
+	if g.Topology.WrapsY() {
+		next.Y = (next.Y + g.Height) % g.Height
+	}
+
+	return next, g.InBounds(next)
+}
+
+// lineThrough returns the run of cells equal to cell that passes through pos
+// along d, ordered from one end to the other. On wrapped boards the run stops
+// before it would visit a cell twice.
+func (g *Game) lineThrough(pos Position, d Position, cell Cell) []Position {
+	seen := map[Position]bool{pos: true}
+
+	var behind []Position
+	back := Position{X: -d.X, Y: -d.Y, Z: -d.Z}
+	for p, ok := g.step(pos, back); ok && !seen[p] && g.Board[p] == cell; p, ok = g.step(p, back) {
+		seen[p] = true
+		behind = append(behind, p)
+	}
+
+	line := make([]Position, 0, len(behind)+1)
+	for i := len(behind) - 1; i >= 0; i-- {
+		line = append(line, behind[i])
+	}
+	line = append(line, pos)
+
+	for p, ok := g.step(pos, d); ok && !seen[p] && g.Board[p] == cell; p, ok = g.step(p, d) {
+		seen[p] = true
+		line = append(line, p)
+	}
+
+	return line
+}
+
+// Lines returns every window of WinRow cells a side could fill to win,
+// following the board topology. Evaluation functions score positions by
+// the windows each side can still complete. On an infinite board only the
+// windows that can reach an occupied cell are returned.
+func (g *Game) Lines() [][]Position {
+	return g.LinesAround(0)
+}
+
+// LinesAround returns the windows of Lines and, on an infinite board, also
+// those reaching the cells up to margin away from the stones, where a
+// search may still place stones.
+func (g *Game) LinesAround(margin int) [][]Position {
+	var lines [][]Position
+	seen := map[string]bool{}
+
+	for line := range g.windows(margin) {
+		// a window as long as a wrapped row is found from every cell of it
+		key := lineKey(line)
+		if !seen[key] {
+			seen[key] = true
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// windows yields the windows of LinesAround without skipping the repeats
+// of a window as long as a wrapped row.
+func (g *Game) windows(margin int) iter.Seq[[]Position] {
+	return func(yield func([]Position) bool) {
+		lo, hi := g.Viewport(g.WinRow - 1 + margin)
+		for z := range g.Layers() {
+			for y := lo.Y; y <= hi.Y; y++ {
+				for x := lo.X; x <= hi.X; x++ {
+					for _, d := range g.directions() {
+						line := g.window(Position{X: x, Y: y, Z: z}, d)
+						if line != nil && !yield(line) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func lineKey(line []Position) string {
+	sorted := slices.Clone(line)
+	slices.SortFunc(sorted, func(a, b Position) int {
+		return cmp.Or(cmp.Compare(a.Z, b.Z), cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+
+	return fmt.Sprint(sorted)
+}
+
+// window returns the WinRow cells starting at start along d, or nil if they
+// leave the board or repeat a cell.
+func (g *Game) window(start Position, d Position) []Position {
+	if !g.InBounds(start) {
+		return nil
+	}
+
+	line := []Position{start}
+	seen := map[Position]bool{start: true}
+
+	p := start
+	for len(line) < g.WinRow {
+		var ok bool
+		p, ok = g.step(p, d)
+		if !ok || seen[p] {
+			return nil
+		}
+
+		seen[p] = true
+		line = append(line, p)
+	}
+
+	return line
+}
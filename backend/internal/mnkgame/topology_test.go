@@ -0,0 +1,95 @@
+package mnkgame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatBoardDoesNotWrap(t *testing.T) {
+	g := NewGame(4, 4, 3)
+
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	assert.Equal(t, StatusTurn, g.Status)
+}
+
+func TestHorizontalWrapWin(t *testing.T) {
+	g := NewGame(4, 4, 3)
+	g.Topology = TopologyWrapX
+
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+
+	assert.Equal(t, StatusWin, g.Status)
+	require.Len(t, g.WinLines, 1)
+	assert.Equal(t, []Position{{X: 3, Y: 0}, {X: 0, Y: 0}, {X: 1, Y: 0}}, g.WinLines[0])
+}
+
+func TestVerticalWrapIgnoresHorizontalEdges(t *testing.T) {
+	g := NewGame(4, 4, 3)
+	g.Topology = TopologyWrapY
+
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	assert.Equal(t, StatusTurn, g.Status)
+
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 3}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 3}))
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 3}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 1}))
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+}
+
+func TestTorusDiagonalWin(t *testing.T) {
+	g := NewGame(4, 4, 4)
+	g.Topology = TopologyTorus
+
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 3}))
+
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Len(t, g.WinLines[0], 4)
+}
+
+func TestWrappedLineDoesNotCountCellsTwice(t *testing.T) {
+	// the columns are tall enough for a line, so the game is not drawn
+	g := NewGame(3, 5, 4)
+	g.Topology = TopologyTorus
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+	assert.Equal(t, StatusTurn, g.Status)
+}
+
+func TestLines(t *testing.T) {
+	g := NewGame(3, 3, 3)
+	assert.Len(t, g.Lines(), 8)
+
+	g.Topology = TopologyWrapX
+	// 3 rows, 3 columns and 3 windows for each diagonal direction
+	assert.Len(t, g.Lines(), 12)
+
+	g = NewGame(4, 4, 3)
+	g.Topology = TopologyTorus
+	assert.Len(t, g.Lines(), 4*4*4)
+}
@@ -0,0 +1,105 @@
+package mnkgame
+
+import "fmt"
+
+// Variant is a two-player rule set defined outside this package, such as
+// by a script. Its hooks run on top of the standard rules.
+type Variant interface {
+	Name() string
+	Description() string
+	// Setup shapes a new game: its dimensions, win row and blocked cells.
+	Setup(g *Game)
+	// Legal reports whether the side to move may play pos. An error means
+	// the rule could not be decided, not that the move is illegal.
+	Legal(g *Game, pos Position) (bool, error)
+	// Outcome decides the game once the stone at pos has been placed.
+	Outcome(g *Game, pos Position) (Outcome, error)
+}
+
+type Outcome int
+
+const (
+	// OutcomeStandard leaves the decision to the standard rules.
+	OutcomeStandard Outcome = iota
+	// OutcomeContinue plays on even if the stone completed a line.
+	OutcomeContinue
+	OutcomeWin
+	OutcomeLoss
+	OutcomeDraw
+)
+
+var outcomeName = map[Outcome]string{
+	OutcomeStandard: "standard",
+	OutcomeContinue: "continue",
+	OutcomeWin:      "win",
+	OutcomeLoss:     "loss",
+	OutcomeDraw:     "draw",
+}
+
+func (o Outcome) String() string {
+	return outcomeName[o]
+}
+
+var (
+	variants     = map[string]Variant{}
+	variantOrder []Variant
+)
+
+// RegisterVariant makes a variant selectable for new games. It panics if
+// the name is taken, as registration happens once at startup.
+func RegisterVariant(v Variant) {
+	if _, ok := variants[v.Name()]; ok {
+		panic(fmt.Sprintf("mnkgame: variant %q registered twice", v.Name()))
+	}
+
+	variants[v.Name()] = v
+	variantOrder = append(variantOrder, v)
+}
+
+func FindVariant(name string) (Variant, bool) {
+	v, ok := variants[name]
+	return v, ok
+}
+
+// Variants returns the registered variants in the order they were
+// registered.
+func Variants() []Variant {
+	return variantOrder
+}
+
+func (g *Game) variant() (Variant, bool) {
+	if g.Variant == "" {
+		return nil, false
+	}
+
+	return FindVariant(g.Variant)
+}
+
+// unplace takes back the last stone placed at pos when a variant fails to
+// decide the position.
+func (g *Game) unplace(pos Position) {
+	g.put(pos, CellEmpty)
+	g.StonesLeft++
+
+	turn := &g.History[len(g.History)-1]
+	turn.Stones = turn.Stones[:len(turn.Stones)-1]
+	if len(turn.Stones) == 0 {
+		g.History = g.History[:len(g.History)-1]
+	}
+}
+
+// LongestRun returns the length of the longest line of equal stones through
+// the stone at pos, or 0 for an empty cell.
+func (g *Game) LongestRun(pos Position) int {
+	cell := g.Board[pos]
+	if cell == CellEmpty {
+		return 0
+	}
+
+	longest := 0
+	for _, d := range g.directions() {
+		longest = max(longest, len(g.lineThrough(pos, d, cell)))
+	}
+
+	return longest
+}
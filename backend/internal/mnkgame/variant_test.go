@@ -0,0 +1,55 @@
+package mnkgame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endlessVariant never lets a line end the game and forbids the centre.
+type endlessVariant struct{}
+
+func (endlessVariant) Name() string        { return "endless" }
+func (endlessVariant) Description() string { return "" }
+
+func (endlessVariant) Setup(g *Game) {
+	g.Width, g.Height, g.WinRow = 3, 3, 3
+	g.Blocked = map[Position]bool{{X: 2, Y: 2}: true}
+}
+
+func (endlessVariant) Legal(g *Game, pos Position) (bool, error) {
+	// the centre is off limits
+	return pos != (Position{X: 1, Y: 1}), nil
+}
+
+func (endlessVariant) Outcome(g *Game, pos Position) (Outcome, error) {
+	return OutcomeContinue, nil
+}
+
+func TestVariantHooks(t *testing.T) {
+	RegisterVariant(endlessVariant{})
+	assert.Panics(t, func() { RegisterVariant(endlessVariant{}) })
+
+	g := CreateGame(CreateGameParams{PlayerID: "x", Width: 9, Height: 9, WinRow: 5, Variant: "endless"})
+	require.Nil(t, BecomeOpponent(g, "o"))
+	assert.Equal(t, 3, g.Width)
+	assert.Equal(t, 7, len(LegalMoves(g)))
+
+	assert.NotNil(t, MakeTurn(g, Position{X: 1, Y: 1}))
+	assert.ErrorIs(t, MakeTurn(g, Position{X: 2, Y: 2}), ErrOutOfBounds)
+
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 0}))
+	require.Nil(t, MakeTurn(g, Position{X: 1, Y: 2}))
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+	assert.Equal(t, 3, g.LongestRun(Position{X: 0, Y: 0}))
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Empty(t, g.WinLines)
+
+	// the centre stays empty, but nothing is left to play
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 1}))
+	require.Nil(t, MakeTurn(g, Position{X: 0, Y: 2}))
+	assert.Equal(t, StatusDraw, g.Status)
+}
@@ -0,0 +1,79 @@
+// Package notation reads and writes m,n,k-game records: "h8"-style cell
+// coordinates, a PGN-like text format and the Gomocup PSQ format.
+package notation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"webgames/internal/mnkgame"
+)
+
+// Coord formats pos the way Gomoku records do: a column letter counted from
+// the left and a row number counted from the bottom, so the centre of a
+// 15x15 board is "h8". Columns after "z" continue with "aa", "ab" and so
+// on. Layers of 3D and Notakto boards follow after a colon ("b2:3"), and
+// infinite boards, which have no bottom or left, use the "(x,y)" form.
+func Coord(g *mnkgame.Game, pos mnkgame.Position) string {
+	if g.Infinite {
+		return pos.String()
+	}
+
+	coord := column(pos.X) + strconv.Itoa(g.Height-pos.Y)
+	if g.Layers() > 1 {
+		coord += ":" + strconv.Itoa(pos.Z+1)
+	}
+
+	return coord
+}
+
+// column returns the letters of the zero-based column x.
+func column(x int) string {
+	letters := ""
+	for x++; x > 0; x = (x - 1) / 26 {
+		letters = string(rune('a'+(x-1)%26)) + letters
+	}
+
+	return letters
+}
+
+// ParseCoord reads a coordinate written by Coord for the same board.
+func ParseCoord(g *mnkgame.Game, s string) (mnkgame.Position, error) {
+	var pos mnkgame.Position
+	if g.Infinite {
+		err := pos.UnmarshalText([]byte(s))
+		return pos, err
+	}
+
+	square, layer, hasLayer := strings.Cut(s, ":")
+	if hasLayer {
+		z, err := strconv.Atoi(layer)
+		if err != nil || z < 1 {
+			return pos, fmt.Errorf("invalid layer in %q", s)
+		}
+		pos.Z = z - 1
+	}
+
+	letters := strings.TrimRight(square, "0123456789")
+	if letters == "" || letters == square {
+		return pos, fmt.Errorf("invalid coordinate %q", s)
+	}
+
+	x := 0
+	for _, r := range letters {
+		if r < 'a' || r > 'z' {
+			return pos, fmt.Errorf("invalid column in %q", s)
+		}
+		x = x*26 + int(r-'a'+1)
+	}
+	pos.X = x - 1
+
+	row, err := strconv.Atoi(square[len(letters):])
+	if err != nil {
+		return pos, fmt.Errorf("invalid row in %q", s)
+	}
+	pos.Y = g.Height - row
+
+	return pos, nil
+}
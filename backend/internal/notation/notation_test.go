@@ -0,0 +1,175 @@
+package notation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"webgames/internal/mnkgame"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoord(t *testing.T) {
+	g := mnkgame.NewGame(15, 15, 5)
+	assert.Equal(t, "h8", Coord(g, mnkgame.Position{X: 7, Y: 7}))
+	assert.Equal(t, "a15", Coord(g, mnkgame.Position{X: 0, Y: 0}))
+	assert.Equal(t, "o1", Coord(g, mnkgame.Position{X: 14, Y: 14}))
+
+	wide := mnkgame.NewGame(50, 50, 5)
+	assert.Equal(t, "z50", Coord(wide, mnkgame.Position{X: 25, Y: 0}))
+	assert.Equal(t, "aa1", Coord(wide, mnkgame.Position{X: 26, Y: 49}))
+	assert.Equal(t, "ax1", Coord(wide, mnkgame.Position{X: 49, Y: 49}))
+
+	cube := mnkgame.NewCubeGame(4, 4)
+	assert.Equal(t, "b3:4", Coord(cube, mnkgame.Position{X: 1, Y: 1, Z: 3}))
+
+	infinite := mnkgame.NewInfiniteGame(5)
+	assert.Equal(t, "(-3,2)", Coord(infinite, mnkgame.Position{X: -3, Y: 2}))
+}
+
+func TestParseCoord(t *testing.T) {
+	for _, g := range []*mnkgame.Game{
+		mnkgame.NewGame(50, 50, 5),
+		mnkgame.NewCubeGame(4, 4),
+		mnkgame.NewInfiniteGame(5),
+	} {
+		for _, pos := range []mnkgame.Position{{X: 0, Y: 0}, {X: 3, Y: 2, Z: 1}, {X: 27, Y: 49}} {
+			if !g.InBounds(pos) && !g.Infinite {
+				continue
+			}
+
+			got, err := ParseCoord(g, Coord(g, pos))
+			require.Nil(t, err)
+			assert.Equal(t, pos, got)
+		}
+	}
+
+	g := mnkgame.NewGame(15, 15, 5)
+	for _, s := range []string{"", "8", "h", "H8", "h8:x", "(1,2)"} {
+		_, err := ParseCoord(g, s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+// play makes up to n moves of g, spread over the legal moves, alternating
+// the marks in Order and Chaos.
+func play(t *testing.T, g *mnkgame.Game, n int) {
+	t.Helper()
+
+	for i := 0; i < n && g.Status == mnkgame.StatusTurn; i++ {
+		moves := mnkgame.LegalMoves(g)
+		mark := mnkgame.CellEmpty
+		if g.Rules.ChooseMark() {
+			mark = mnkgame.Cell(i%2 + 1)
+		}
+
+		require.Nil(t, mnkgame.MakeMarkedTurn(g, moves[i*7%len(moves)], mark))
+	}
+}
+
+func assertSameGame(t *testing.T, want, got *mnkgame.Game) {
+	t.Helper()
+
+	assert.Equal(t, want.Board, got.Board)
+	assert.Equal(t, want.History, got.History)
+	assert.Equal(t, want.StatusText(), got.StatusText())
+	assert.Equal(t, want.Turn, got.Turn)
+	assert.Equal(t, want.Rules, got.Rules)
+	assert.Equal(t, want.Geometry, got.Geometry)
+	assert.Equal(t, want.Width, got.Width)
+	assert.Equal(t, want.Layers(), got.Layers())
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	var games []*mnkgame.Game
+	for _, preset := range mnkgame.Presets {
+		games = append(games, mnkgame.NewGameFromParams(preset.Params))
+	}
+	games = append(games,
+		mnkgame.NewGameFromParams(mnkgame.CreateGameParams{Infinite: true, WinRow: 4}),
+		mnkgame.NewGameFromParams(mnkgame.CreateGameParams{Geometry: mnkgame.GeometryHex, Width: 4, WinRow: 4}),
+		mnkgame.NewGameFromParams(mnkgame.CreateGameParams{
+			Width: 5, Height: 5, WinRow: 3, Players: 3, Scoring: mnkgame.ScoringElimination,
+			Topology: mnkgame.TopologyTorus,
+		}),
+	)
+
+	for _, g := range games {
+		play(t, g, 30)
+
+		var buf bytes.Buffer
+		require.Nil(t, WriteText(&buf, g))
+
+		got, err := ReadText(&buf)
+		require.Nil(t, err, buf.String())
+		assertSameGame(t, g, got)
+	}
+}
+
+func TestReadText(t *testing.T) {
+	record := `[Width "3"]
+[Height "3"]
+[WinRow "3"]
+[Result "Win X"]
+
+1. a3 a1
+2. b2 a2
+3. c1`
+
+	g, err := ReadText(strings.NewReader(record))
+	require.Nil(t, err)
+	assert.Equal(t, mnkgame.StatusWin, g.Status)
+	assert.Equal(t, mnkgame.PlayerX, g.Winner)
+	assert.Equal(t, mnkgame.CellX, g.Board[mnkgame.Position{X: 1, Y: 1}])
+	assert.Equal(t, mnkgame.CellO, g.Board[mnkgame.Position{X: 0, Y: 2}])
+
+	_, err = ReadText(strings.NewReader(strings.Replace(record, "Win X", "Draw", 1)))
+	assert.NotNil(t, err)
+
+	_, err = ReadText(strings.NewReader(strings.Replace(record, "3. c1", "3. b2", 1)))
+	assert.NotNil(t, err)
+
+	_, err = ReadText(strings.NewReader(`[Rules "Chess"]` + "\n" + record))
+	assert.NotNil(t, err)
+	for _, size := range []string{"0", "51", "100000"} {
+		_, err = ReadText(strings.NewReader(strings.Replace(record, `[Width "3"]`, `[Width "`+size+`"]`, 1)))
+		assert.NotNil(t, err, "width %s", size)
+		_, err = ReadText(strings.NewReader(strings.Replace(record, `[Height "3"]`, `[Height "`+size+`"]`, 1)))
+		assert.NotNil(t, err, "height %s", size)
+	}
+}
+
+func TestPSQRoundTrip(t *testing.T) {
+	g := mnkgame.NewGame(15, 15, PSQWinRow)
+	play(t, g, 40)
+
+	var buf bytes.Buffer
+	require.Nil(t, WritePSQ(&buf, g))
+	assert.True(t, strings.HasPrefix(buf.String(), "Piskvorky 15x15, 11:11, 0\n"))
+
+	got, err := ReadPSQ(&buf, mnkgame.RulesStandard)
+	require.Nil(t, err)
+	assertSameGame(t, g, got)
+
+	assert.NotNil(t, WritePSQ(&buf, mnkgame.NewInfiniteGame(5)))
+}
+
+func TestReadPSQ(t *testing.T) {
+	record := "Piskvorky 20x20, 11:11, 0\n10,10,2969\n11,11,703\n10,11,0\n-1\npbrain-embryo.exe\n"
+
+	g, err := ReadPSQ(strings.NewReader(record), mnkgame.RulesStandard)
+	require.Nil(t, err)
+	assert.Equal(t, 20, g.Width)
+	assert.Len(t, g.History, 3)
+	assert.Equal(t, mnkgame.CellO, g.Board[mnkgame.Position{X: 10, Y: 10}])
+	assert.Equal(t, mnkgame.PlayerO, g.Turn)
+
+	_, err = ReadPSQ(strings.NewReader("Renju 15x15\n"), mnkgame.RulesStandard)
+	assert.NotNil(t, err)
+	for _, header := range []string{"Piskvorky 100000x100000,", "Piskvorky 15x51,", "Piskvorky 0x15,"} {
+		_, err = ReadPSQ(strings.NewReader(header+"\n-1\n"), mnkgame.RulesStandard)
+		assert.NotNil(t, err, header)
+	}
+}
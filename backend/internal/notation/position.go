@@ -0,0 +1,239 @@
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"webgames/internal/mnkgame"
+)
+
+// MaxPositionSize is the largest board side a position string or an
+// imported record may have.
+const MaxPositionSize = 50
+
+// checkSize returns an error if a bounded board of the given sides is empty
+// or larger than MaxPositionSize allows.
+func checkSize(width, height int) error {
+	if width < 1 || width > MaxPositionSize || height < 1 || height > MaxPositionSize {
+		return fmt.Errorf("a board of %dx%d is not between 1x1 and %dx%d", width, height, MaxPositionSize, MaxPositionSize)
+	}
+	return nil
+}
+
+var markLetter = map[mnkgame.Cell]byte{
+	mnkgame.CellX:        'x',
+	mnkgame.CellO:        'o',
+	mnkgame.CellTriangle: 't',
+	mnkgame.CellSquare:   's',
+}
+
+func letterMark(b byte) (mnkgame.Cell, bool) {
+	for cell, letter := range markLetter {
+		if letter == b {
+			return cell, true
+		}
+	}
+
+	return mnkgame.CellEmpty, false
+}
+
+// FormatPosition encodes the board of g in a single line modelled on FEN.
+// The four fields are the dimensions, the win row, the cells and the side
+// to move:
+//
+//	3x3 3 3/1x1/3 o
+//
+// The cells list the rows from the top, separated by slashes, with the
+// layers of a cube separated by "|". A row gives the marks as letters (x,
+// o, t for △ and s for □) and runs of empty cells as numbers. Only single
+// square boards and cubes of a bounded size have a position string.
+func FormatPosition(g *mnkgame.Game) (string, error) {
+	if g.Infinite || g.Geometry == mnkgame.GeometryHex {
+		return "", errors.New("only square and cube boards have a position string")
+	}
+	// the boards of Notakto would read back as the layers of a cube
+	if g.Geometry == mnkgame.GeometrySquare && g.Layers() > 1 {
+		return "", errors.New("only single square boards and cubes have a position string")
+	}
+
+	dims := fmt.Sprintf("%dx%d", g.Width, g.Height)
+	if g.Geometry == mnkgame.GeometryCube {
+		dims += fmt.Sprintf("x%d", g.Layers())
+	}
+
+	layers := make([]string, g.Layers())
+	for z := range layers {
+		rows := make([]string, g.Height)
+		for y := range rows {
+			var row strings.Builder
+			empty := 0
+			for x := range g.Width {
+				cell := g.Board[mnkgame.Position{X: x, Y: y, Z: z}]
+				if cell == mnkgame.CellEmpty {
+					empty++
+					continue
+				}
+
+				if empty > 0 {
+					row.WriteString(strconv.Itoa(empty))
+					empty = 0
+				}
+				row.WriteByte(markLetter[cell])
+			}
+			if empty > 0 {
+				row.WriteString(strconv.Itoa(empty))
+			}
+
+			rows[y] = row.String()
+		}
+
+		layers[z] = strings.Join(rows, "/")
+	}
+
+	turn := g.Turn
+	if turn == 0 {
+		turn = mnkgame.PlayerX
+	}
+
+	return fmt.Sprintf("%s %d %s %c", dims, g.WinRow, strings.Join(layers, "|"), markLetter[turn.Cell()]), nil
+}
+
+// ParsePosition sets up a game from a position string. The game has the
+// standard rules, one side per mark used and no history; it is not stored.
+// The position may be one no game can reach, see CheckPlayable.
+func ParsePosition(s string) (*mnkgame.Game, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("a position has 4 fields, %q has %d", s, len(fields))
+	}
+
+	var dims []int
+	for _, d := range strings.Split(fields[0], "x") {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 1 || n > MaxPositionSize {
+			return nil, fmt.Errorf("invalid dimensions %q", fields[0])
+		}
+		dims = append(dims, n)
+	}
+
+	params := mnkgame.CreateGameParams{Players: 2}
+	switch {
+	case len(dims) == 2:
+		params.Width, params.Height = dims[0], dims[1]
+	case len(dims) == 3 && dims[0] == dims[1] && dims[1] == dims[2]:
+		params.Width, params.Height = dims[0], dims[0]
+		params.Geometry = mnkgame.GeometryCube
+	default:
+		return nil, fmt.Errorf("invalid dimensions %q, a cube has equal sides", fields[0])
+	}
+
+	winRow, err := strconv.Atoi(fields[1])
+	if err != nil || winRow < 1 || winRow > max(params.Width, params.Height) {
+		return nil, fmt.Errorf("invalid win row %q", fields[1])
+	}
+	params.WinRow = winRow
+
+	turnMark, ok := letterMark(fields[3][0])
+	if !ok || len(fields[3]) != 1 {
+		return nil, fmt.Errorf("invalid side to move %q", fields[3])
+	}
+
+	board, err := parseCells(fields[2], dims)
+	if err != nil {
+		return nil, err
+	}
+
+	// every mark used needs a side to play it
+	for _, cell := range board {
+		params.Players = max(params.Players, int(cell))
+	}
+	params.Players = max(params.Players, int(turnMark))
+
+	g := mnkgame.NewGameFromParams(params)
+	g.Board = board
+	g.Hash = board.Hash()
+	g.Turn = mnkgame.Player(turnMark)
+
+	return g, nil
+}
+
+// CheckPlayable returns an error if a game cannot go on from the position
+// of g, because a side already has a line or the board is full.
+func CheckPlayable(g *mnkgame.Game) error {
+	for _, line := range g.Lines() {
+		if complete(g, line) {
+			return fmt.Errorf("the position already has a line at %s", Coord(g, line[0]))
+		}
+	}
+
+	if len(mnkgame.LegalMoves(g)) == 0 {
+		return errors.New("the position has no empty cell left")
+	}
+
+	return nil
+}
+
+func parseCells(s string, dims []int) (mnkgame.Board, error) {
+	width, height, depth := dims[0], dims[1], 1
+	if len(dims) == 3 {
+		depth = dims[2]
+	}
+
+	board := mnkgame.Board{}
+	layers := strings.Split(s, "|")
+	if len(layers) != depth {
+		return nil, fmt.Errorf("the position has %d layers instead of %d", len(layers), depth)
+	}
+
+	for z, layer := range layers {
+		rows := strings.Split(layer, "/")
+		if len(rows) != height {
+			return nil, fmt.Errorf("the position has %d rows instead of %d", len(rows), height)
+		}
+
+		for y, row := range rows {
+			x := 0
+			for i := 0; i < len(row); i++ {
+				if cell, ok := letterMark(row[i]); ok {
+					board[mnkgame.Position{X: x, Y: y, Z: z}] = cell
+					x++
+					continue
+				}
+
+				j := i
+				for j < len(row) && row[j] >= '0' && row[j] <= '9' {
+					j++
+				}
+				empty, err := strconv.Atoi(row[i:j])
+				if err != nil || empty < 1 {
+					return nil, fmt.Errorf("invalid row %q", row)
+				}
+				x += empty
+				i = j - 1
+			}
+
+			if x != width {
+				return nil, fmt.Errorf("row %q has %d cells instead of %d", row, x, width)
+			}
+		}
+	}
+
+	return board, nil
+}
+
+func complete(g *mnkgame.Game, line []mnkgame.Position) bool {
+	first := g.Board[line[0]]
+	if first == mnkgame.CellEmpty {
+		return false
+	}
+
+	for _, pos := range line[1:] {
+		if g.Board[pos] != first {
+			return false
+		}
+	}
+
+	return true
+}
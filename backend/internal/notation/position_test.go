@@ -0,0 +1,123 @@
+package notation
+
+import (
+	"testing"
+
+	"webgames/internal/mnkgame"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatPosition(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 1, Y: 1}))
+
+	s, err := FormatPosition(g)
+	require.Nil(t, err)
+	assert.Equal(t, "3x3 3 3/1x1/3 o", s)
+
+	g = mnkgame.NewGame(15, 15, 5)
+	s, err = FormatPosition(g)
+	require.Nil(t, err)
+	assert.Equal(t, "15x15 5 15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 x", s)
+
+	_, err = FormatPosition(mnkgame.NewInfiniteGame(5))
+	assert.NotNil(t, err)
+	_, err = FormatPosition(mnkgame.NewHexGame(4, 4))
+	assert.NotNil(t, err)
+}
+
+func TestNotaktoHasNoPosition(t *testing.T) {
+	g := mnkgame.NewGameFromParams(mnkgame.CreateGameParams{
+		Width: 3, Height: 3, WinRow: 3, Rules: mnkgame.RulesNotakto, Boards: 3,
+	})
+	require.Equal(t, 3, g.Layers())
+	play(t, g, 3)
+
+	_, err := FormatPosition(g)
+	assert.NotNil(t, err, "the boards would read back as a cube")
+}
+
+func TestPresetPositionsRoundTrip(t *testing.T) {
+	for _, preset := range mnkgame.Presets {
+		g := mnkgame.NewGameFromParams(preset.Params)
+		s, err := FormatPosition(g)
+		if err != nil {
+			continue
+		}
+
+		got, err := ParsePosition(s)
+		require.Nil(t, err, "%s: %s", preset.Name, s)
+		assert.Equal(t, g.Layers(), got.Layers(), preset.Name)
+		assert.Equal(t, g.Geometry, got.Geometry, preset.Name)
+	}
+}
+
+func TestPositionRoundTrip(t *testing.T) {
+	for _, g := range []*mnkgame.Game{
+		mnkgame.NewGame(15, 15, 5),
+		mnkgame.NewGame(12, 7, 4),
+		mnkgame.NewCubeGame(4, 4),
+		mnkgame.NewMultiplayerGame(6, 6, 3, 4, mnkgame.ScoringFirstToK),
+	} {
+		play(t, g, 9)
+
+		s, err := FormatPosition(g)
+		require.Nil(t, err)
+
+		got, err := ParsePosition(s)
+		require.Nil(t, err, s)
+		assert.Equal(t, g.Board, got.Board, s)
+		assert.Equal(t, g.Turn, got.Turn, s)
+		assert.Equal(t, g.WinRow, got.WinRow, s)
+		assert.Equal(t, g.Layers(), got.Layers(), s)
+
+		again, err := FormatPosition(got)
+		require.Nil(t, err)
+		assert.Equal(t, s, again)
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	g, err := ParsePosition("4x3 3 x2o/t3/4 s")
+	require.Nil(t, err)
+	assert.Equal(t, 4, g.Players)
+	assert.Equal(t, mnkgame.PlayerSquare, g.Turn)
+	assert.Equal(t, mnkgame.CellO, g.Board[mnkgame.Position{X: 3, Y: 0}])
+	assert.Equal(t, mnkgame.CellTriangle, g.Board[mnkgame.Position{X: 0, Y: 1}])
+	assert.Equal(t, mnkgame.StatusTurn, g.Status)
+	assert.Empty(t, g.History)
+
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 3, Y: 2}))
+	assert.Equal(t, mnkgame.PlayerX, g.Turn)
+
+	for _, s := range []string{
+		"",
+		"3x3 3 3/3/3",
+		"3x3 3 3/3 x",
+		"3x3 3 3/4/3 x",
+		"3x3 3 3/2q/3 x",
+		"3x3 4 3/3/3 x",
+		"3x4x3 3 3/3/3 x",
+		"51x3 3 51/51/51 x",
+		"3x3 3 3/3/3 q",
+	} {
+		_, err := ParsePosition(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestCheckPlayable(t *testing.T) {
+	g, err := ParsePosition("3x3 3 xxx/oo1/3 o")
+	require.Nil(t, err)
+	assert.NotNil(t, CheckPlayable(g))
+
+	g, err = ParsePosition("3x3 3 xox/xoo/oxx o")
+	require.Nil(t, err)
+	assert.NotNil(t, CheckPlayable(g))
+
+	g, err = ParsePosition("3x3 3 xx1/oo1/3 o")
+	require.Nil(t, err)
+	assert.Nil(t, CheckPlayable(g))
+}
@@ -0,0 +1,89 @@
+package notation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"webgames/internal/mnkgame"
+)
+
+// PSQWinRow is the line length of PSQ games, which are Gomoku records.
+const PSQWinRow = 5
+
+// WritePSQ writes g in the Gomocup PSQ format: a header with the board size
+// followed by one "x,y,time" line per stone with 1-based coordinates. PSQ
+// only describes two sides placing one stone per turn on a flat board.
+func WritePSQ(w io.Writer, g *mnkgame.Game) error {
+	if g.Players != 2 || g.Infinite || g.Geometry != mnkgame.GeometrySquare ||
+		g.Layers() > 1 || g.FirstTurnStones != 1 || g.StonesPerTurn != 1 ||
+		g.Rules.ChooseMark() {
+		return errors.New("PSQ only records two-sided games with one stone per turn on a flat board")
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "Piskvorky %dx%d, 11:11, 0\n", g.Width, g.Height)
+	for _, turn := range g.History {
+		for _, pos := range turn.Stones {
+			fmt.Fprintf(bw, "%d,%d,0\n", pos.X+1, pos.Y+1)
+		}
+	}
+	bw.WriteString("-1\n")
+
+	return bw.Flush()
+}
+
+// ReadPSQ reads a PSQ record and replays it as a Gomoku game of the given
+// rules, which PSQ does not record. The move list ends at the first line
+// that is not a move, such as the "-1" terminator or the engine names some
+// files carry.
+func ReadPSQ(r io.Reader, rules mnkgame.Rules) (*mnkgame.Game, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, errors.New("the PSQ record is empty")
+	}
+
+	var width, height int
+	if _, err := fmt.Sscanf(scanner.Text(), "Piskvorky %dx%d,", &width, &height); err != nil {
+		return nil, fmt.Errorf("invalid PSQ header %q: %w", scanner.Text(), err)
+	}
+	if err := checkSize(width, height); err != nil {
+		return nil, err
+	}
+
+	g := mnkgame.NewGameFromParams(mnkgame.CreateGameParams{
+		Players:         2,
+		Rules:           rules,
+		Width:           width,
+		Height:          height,
+		WinRow:          PSQWinRow,
+		FirstTurnStones: 1,
+		StonesPerTurn:   1,
+	})
+
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ",")
+		if len(fields) < 2 {
+			break
+		}
+
+		x, errX := strconv.Atoi(fields[0])
+		y, errY := strconv.Atoi(fields[1])
+		if errX != nil || errY != nil {
+			break
+		}
+
+		pos := mnkgame.Position{X: x - 1, Y: y - 1}
+		if err := mnkgame.MakeTurn(g, pos); err != nil {
+			return nil, fmt.Errorf("move %d,%d: %w", x, y, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
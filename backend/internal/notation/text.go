@@ -0,0 +1,266 @@
+package notation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"webgames/internal/mnkgame"
+)
+
+// WriteText writes g as a text record modelled on PGN: tag pairs with the
+// settings of the game followed by the move text, one round per line.
+//
+//	[Width "15"]
+//	[Height "15"]
+//	[WinRow "5"]
+//	...
+//	[Result "Win X"]
+//
+//	1. h8 i9
+//	2. g7 j10
+//
+// The stones of a multi-stone turn are joined by commas ("h8,i9"), and a
+// mark chosen in Order and Chaos follows an equals sign ("h8=O").
+func WriteText(w io.Writer, g *mnkgame.Game) error {
+	bw := bufio.NewWriter(w)
+
+	for _, tag := range tags(g) {
+		fmt.Fprintf(bw, "[%s %q]\n", tag[0], tag[1])
+	}
+	bw.WriteString("\n")
+
+	players := max(g.Players, 1)
+	for i, turn := range g.History {
+		if i%players == 0 {
+			if i > 0 {
+				bw.WriteString("\n")
+			}
+			fmt.Fprintf(bw, "%d.", i/players+1)
+		}
+
+		stones := make([]string, len(turn.Stones))
+		for j, pos := range turn.Stones {
+			stones[j] = Coord(g, pos)
+			if g.Rules.ChooseMark() {
+				stones[j] += "=" + g.Board[pos].String()
+			}
+		}
+		bw.WriteString(" " + strings.Join(stones, ","))
+	}
+	if len(g.History) > 0 {
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
+
+// tags returns the tag pairs of g in the order they are written.
+func tags(g *mnkgame.Game) [][2]string {
+	width := g.Width
+	if g.Geometry == mnkgame.GeometryHex {
+		width = g.HexSide()
+	}
+
+	tags := [][2]string{
+		{"Width", strconv.Itoa(width)},
+		{"Height", strconv.Itoa(g.Height)},
+		{"WinRow", strconv.Itoa(g.WinRow)},
+		{"Players", strconv.Itoa(g.Players)},
+		{"Scoring", g.Scoring.String()},
+		{"Rules", g.Rules.String()},
+		{"Geometry", g.Geometry.String()},
+		{"Topology", g.Topology.String()},
+		{"Infinite", strconv.FormatBool(g.Infinite)},
+		{"FirstTurnStones", strconv.Itoa(g.FirstTurnStones)},
+		{"StonesPerTurn", strconv.Itoa(g.StonesPerTurn)},
+	}
+	if g.Rules == mnkgame.RulesNotakto {
+		tags = append(tags, [2]string{"Boards", strconv.Itoa(g.Layers())})
+	}
+	if g.Variant != "" {
+		tags = append(tags, [2]string{"Variant", g.Variant})
+	}
+
+	return append(tags, [2]string{"Result", g.StatusText()})
+}
+
+var tagPattern = regexp.MustCompile(`^\[(\w+)\s+"(.*)"\]$`)
+
+// ReadText reads a record written by WriteText and replays its moves. The
+// returned game has no seats and is not stored.
+func ReadText(r io.Reader) (*mnkgame.Game, error) {
+	tags := map[string]string{}
+	var moves []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := tagPattern.FindStringSubmatch(line); m != nil {
+			value, err := strconv.Unquote(`"` + m[2] + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tag %s: %w", m[1], err)
+			}
+			tags[m[1]] = value
+			continue
+		}
+
+		moves = append(moves, strings.Fields(line)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	params, err := readParams(tags)
+	if err != nil {
+		return nil, err
+	}
+	g := mnkgame.NewGameFromParams(params)
+
+	for _, token := range moves {
+		// move numbers only help the reader
+		if strings.HasSuffix(token, ".") {
+			continue
+		}
+
+		for _, stone := range splitStones(token) {
+			coord, mark, _ := strings.Cut(stone, "=")
+			pos, err := ParseCoord(g, coord)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := mnkgame.MakeMarkedTurn(g, pos, parseMark(mark)); err != nil {
+				return nil, fmt.Errorf("move %s: %w", stone, err)
+			}
+		}
+	}
+
+	if result, ok := tags["Result"]; ok && result != g.StatusText() {
+		return nil, fmt.Errorf("the record gives the result %q, its moves %q", result, g.StatusText())
+	}
+
+	return g, nil
+}
+
+// splitStones splits a turn at the commas between its stones, leaving the
+// commas inside "(x,y)" coordinates alone.
+func splitStones(token string) []string {
+	var stones []string
+	depth, start := 0, 0
+	for i, r := range token {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				stones = append(stones, token[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(stones, token[start:])
+}
+
+func readParams(tags map[string]string) (mnkgame.CreateGameParams, error) {
+	params := mnkgame.CreateGameParams{
+		Players:         2,
+		FirstTurnStones: 1,
+		StonesPerTurn:   1,
+		Variant:         tags["Variant"],
+	}
+
+	if params.Variant != "" {
+		if _, ok := mnkgame.FindVariant(params.Variant); !ok {
+			return params, fmt.Errorf("unknown variant %q", params.Variant)
+		}
+	}
+
+	var err error
+	for _, setting := range []struct {
+		tag   string
+		value *int
+	}{
+		{"Width", &params.Width},
+		{"Height", &params.Height},
+		{"WinRow", &params.WinRow},
+		{"Players", &params.Players},
+		{"Boards", &params.Boards},
+		{"FirstTurnStones", &params.FirstTurnStones},
+		{"StonesPerTurn", &params.StonesPerTurn},
+	} {
+		if s, ok := tags[setting.tag]; ok {
+			if *setting.value, err = strconv.Atoi(s); err != nil {
+				return params, fmt.Errorf("invalid tag %s: %w", setting.tag, err)
+			}
+		}
+	}
+
+	if s, ok := tags["Infinite"]; ok {
+		if params.Infinite, err = strconv.ParseBool(s); err != nil {
+			return params, fmt.Errorf("invalid tag Infinite: %w", err)
+		}
+	}
+
+	if params.Scoring, err = parseName[mnkgame.Scoring]("Scoring", tags); err != nil {
+		return params, err
+	}
+	if params.Rules, err = parseName[mnkgame.Rules]("Rules", tags); err != nil {
+		return params, err
+	}
+	if params.Geometry, err = parseName[mnkgame.Geometry]("Geometry", tags); err != nil {
+		return params, err
+	}
+	if params.Topology, err = parseName[mnkgame.Topology]("Topology", tags); err != nil {
+		return params, err
+	}
+
+	if params.WinRow < 1 && params.Variant == "" {
+		return params, fmt.Errorf("the record has no WinRow tag")
+	}
+	// infinite boards grow with the stones and variants bring their own
+	if !params.Infinite && params.Variant == "" {
+		if err := checkSize(params.Width, params.Height); err != nil {
+			return params, err
+		}
+	}
+
+	return params, nil
+}
+
+// parseName looks up the value of an enum by the name its String method
+// gives. The values start at zero and have no gaps; a missing tag is zero.
+func parseName[T interface {
+	~int
+	String() string
+}](tag string, tags map[string]string) (T, error) {
+	name, ok := tags[tag]
+	if !ok {
+		return 0, nil
+	}
+
+	for v := T(0); v.String() != ""; v++ {
+		if v.String() == name {
+			return v, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid tag %s: unknown name %q", tag, name)
+}
+
+func parseMark(s string) mnkgame.Cell {
+	switch s {
+	case mnkgame.CellX.String():
+		return mnkgame.CellX
+	case mnkgame.CellO.String():
+		return mnkgame.CellO
+	}
+
+	return mnkgame.CellEmpty
+}
@@ -0,0 +1,301 @@
+package reversi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Size is the side length of the board.
+const Size = 8
+
+type GameID string
+type PlayerID string
+
+// Game is a game of Reversi (Othello) on an 8x8 board. Board is indexed by
+// row, then column.
+type Game struct {
+	ID     GameID
+	Seats  []PlayerID
+	Board  [Size][Size]Cell
+	Status Status
+	Turn   Player
+	Winner Player
+	// Passed is the side that had no legal move and had to pass the turn
+	// back, reset by the next move.
+	Passed  Player
+	History []Turn
+}
+
+// Turn is a disc placed by a side together with the discs it flipped.
+type Turn struct {
+	Player  Player
+	Disc    Position
+	Flipped []Position
+}
+
+type Player int
+
+const (
+	PlayerBlack Player = iota + 1
+	PlayerWhite
+)
+
+func (p Player) Cell() Cell {
+	return Cell(p)
+}
+
+func (p Player) Opponent() Player {
+	if p == PlayerBlack {
+		return PlayerWhite
+	}
+
+	return PlayerBlack
+}
+
+var playerName = map[Player]string{
+	PlayerBlack: "Black",
+	PlayerWhite: "White",
+}
+
+func (p Player) String() string {
+	return playerName[p]
+}
+
+type Cell int
+
+const (
+	CellEmpty Cell = iota
+	CellBlack
+	CellWhite
+)
+
+func (c Cell) String() string {
+	switch c {
+	case CellBlack:
+		return "●"
+	case CellWhite:
+		return "○"
+	}
+
+	return " "
+}
+
+type Status int
+
+const (
+	StatusOpponent Status = iota
+	StatusTurn
+	StatusWin
+	StatusDraw
+)
+
+var statusName = map[Status]string{
+	StatusOpponent: "Need opponent",
+	StatusTurn:     "Turn",
+	StatusWin:      "Win",
+	StatusDraw:     "Draw",
+}
+
+func (s Status) String() string {
+	return statusName[s]
+}
+
+func (g *Game) StatusText() string {
+	switch g.Status {
+	case StatusTurn:
+		return fmt.Sprintf("%s %s", g.Status, g.Turn)
+	case StatusWin:
+		return fmt.Sprintf("%s %s", g.Status, g.Winner)
+	}
+
+	return g.Status.String()
+}
+
+// PlayerID returns the player seated as the given side.
+func (g *Game) PlayerID(p Player) PlayerID {
+	if p < PlayerBlack || int(p) > len(g.Seats) {
+		return ""
+	}
+
+	return g.Seats[p-1]
+}
+
+type Position struct {
+	X int
+	Y int
+}
+
+func (p Position) InBounds() bool {
+	return p.X >= 0 && p.X < Size && p.Y >= 0 && p.Y < Size
+}
+
+type CreateGameParams struct {
+	PlayerID string
+}
+
+var gamesRepository = map[GameID]*Game{}
+
+func CreateGame(params CreateGameParams) *Game {
+	game := NewGame()
+	game.Seats = []PlayerID{PlayerID(params.PlayerID)}
+	game.Status = StatusOpponent
+	game.Turn = 0
+
+	gamesRepository[game.ID] = game
+	return game
+}
+
+// NewGame returns a game in the standard starting position with Black to
+// move.
+func NewGame() *Game {
+	game := &Game{
+		ID:     GameID(uuid.NewString()),
+		Status: StatusTurn,
+		Turn:   PlayerBlack,
+	}
+
+	game.Board[3][3] = CellWhite
+	game.Board[3][4] = CellBlack
+	game.Board[4][3] = CellBlack
+	game.Board[4][4] = CellWhite
+
+	return game
+}
+
+func FindGame(id GameID) (*Game, bool) {
+	game, ok := gamesRepository[id]
+	return game, ok
+}
+
+func BecomeOpponent(game *Game, playerID PlayerID) error {
+	if game.Status != StatusOpponent {
+		return fmt.Errorf("invalid game status: %s", game.Status)
+	}
+
+	if slices.Contains(game.Seats, playerID) {
+		return errors.New("player has already joined the game")
+	}
+
+	game.Seats = append(game.Seats, playerID)
+	game.Status = StatusTurn
+	game.Turn = PlayerBlack
+
+	return nil
+}
+
+var directions = [8]Position{
+	{X: 1, Y: 0}, {X: -1, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: -1},
+	{X: 1, Y: 1}, {X: -1, Y: -1}, {X: 1, Y: -1}, {X: -1, Y: 1},
+}
+
+func (g *Game) cell(pos Position) Cell {
+	return g.Board[pos.Y][pos.X]
+}
+
+// Flips returns the discs side p would flip by playing pos. A move is legal
+// exactly when it flips at least one disc.
+func (g *Game) Flips(p Player, pos Position) []Position {
+	if !pos.InBounds() || g.cell(pos) != CellEmpty {
+		return nil
+	}
+
+	var flips []Position
+	for _, d := range directions {
+		var run []Position
+		next := Position{X: pos.X + d.X, Y: pos.Y + d.Y}
+		for next.InBounds() && g.cell(next) == p.Opponent().Cell() {
+			run = append(run, next)
+			next = Position{X: next.X + d.X, Y: next.Y + d.Y}
+		}
+
+		// the run only flips when it is closed by a disc of the mover
+		if len(run) > 0 && next.InBounds() && g.cell(next) == p.Cell() {
+			flips = append(flips, run...)
+		}
+	}
+
+	return flips
+}
+
+// LegalMoves returns the cells side p may play, in row-major order.
+func (g *Game) LegalMoves(p Player) []Position {
+	var moves []Position
+	for y := range Size {
+		for x := range Size {
+			pos := Position{X: x, Y: y}
+			if len(g.Flips(p, pos)) > 0 {
+				moves = append(moves, pos)
+			}
+		}
+	}
+
+	return moves
+}
+
+// Count returns the number of discs of the given colour on the board.
+func (g *Game) Count(cell Cell) int {
+	n := 0
+	for _, row := range g.Board {
+		for _, c := range row {
+			if c == cell {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+func MakeTurn(g *Game, pos Position) error {
+	if !pos.InBounds() || g.cell(pos) != CellEmpty {
+		return errors.New("Position is out of bounds or cell is already occupied")
+	}
+
+	if g.Status != StatusTurn {
+		return fmt.Errorf("Game has already ended with status: %s", g.Status)
+	}
+
+	flips := g.Flips(g.Turn, pos)
+	if len(flips) == 0 {
+		return errors.New("Move does not flip any disc")
+	}
+
+	g.Board[pos.Y][pos.X] = g.Turn.Cell()
+	for _, f := range flips {
+		g.Board[f.Y][f.X] = g.Turn.Cell()
+	}
+	g.History = append(g.History, Turn{Player: g.Turn, Disc: pos, Flipped: flips})
+	g.Passed = 0
+
+	// the opponent moves if it can, otherwise it passes back; when neither
+	// side can move the game is over
+	switch {
+	case len(g.LegalMoves(g.Turn.Opponent())) > 0:
+		g.Turn = g.Turn.Opponent()
+	case len(g.LegalMoves(g.Turn)) > 0:
+		g.Passed = g.Turn.Opponent()
+	default:
+		g.finish()
+	}
+
+	return nil
+}
+
+// finish decides the game by the disc count.
+func (g *Game) finish() {
+	black, white := g.Count(CellBlack), g.Count(CellWhite)
+
+	switch {
+	case black > white:
+		g.Status = StatusWin
+		g.Winner = PlayerBlack
+	case white > black:
+		g.Status = StatusWin
+		g.Winner = PlayerWhite
+	default:
+		g.Status = StatusDraw
+	}
+}
@@ -0,0 +1,69 @@
+package reversi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGame(t *testing.T) {
+	g := NewGame()
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Equal(t, PlayerBlack, g.Turn)
+	assert.Equal(t, 2, g.Count(CellBlack))
+	assert.Equal(t, 2, g.Count(CellWhite))
+	assert.Equal(t, []Position{{X: 3, Y: 2}, {X: 2, Y: 3}, {X: 5, Y: 4}, {X: 4, Y: 5}}, g.LegalMoves(PlayerBlack))
+}
+
+func TestMoveFlipsDiscs(t *testing.T) {
+	g := NewGame()
+
+	require.Nil(t, MakeTurn(g, Position{X: 3, Y: 2}))
+	assert.Equal(t, CellBlack, g.Board[3][3])
+	assert.Equal(t, 4, g.Count(CellBlack))
+	assert.Equal(t, 1, g.Count(CellWhite))
+	assert.Equal(t, PlayerWhite, g.Turn)
+
+	// the diagonal closes on the disc just played
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 2}))
+	assert.Equal(t, CellWhite, g.Board[3][3])
+	assert.Equal(t, []Position{{X: 3, Y: 3}}, g.History[1].Flipped)
+}
+
+func TestIllegalMoves(t *testing.T) {
+	g := NewGame()
+
+	assert.NotNil(t, MakeTurn(g, Position{X: 3, Y: 3}))
+	assert.NotNil(t, MakeTurn(g, Position{X: 0, Y: 0}))
+	assert.NotNil(t, MakeTurn(g, Position{X: 8, Y: 0}))
+	assert.Empty(t, g.History)
+}
+
+func TestPassAndDiscCount(t *testing.T) {
+	g := NewGame()
+	g.Board = [Size][Size]Cell{}
+	g.Board[0][0] = CellBlack
+	g.Board[0][1] = CellWhite
+	g.Board[7][0] = CellBlack
+	g.Board[7][1] = CellWhite
+
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 0}))
+	assert.Equal(t, PlayerBlack, g.Turn)
+	assert.Equal(t, PlayerWhite, g.Passed)
+
+	require.Nil(t, MakeTurn(g, Position{X: 2, Y: 7}))
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerBlack, g.Winner)
+	assert.Equal(t, 6, g.Count(CellBlack))
+}
+
+func TestBecomeOpponent(t *testing.T) {
+	g := CreateGame(CreateGameParams{PlayerID: "a"})
+	assert.Equal(t, StatusOpponent, g.Status)
+	assert.NotNil(t, BecomeOpponent(g, "a"))
+
+	require.Nil(t, BecomeOpponent(g, "b"))
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Equal(t, PlayerID("b"), g.PlayerID(PlayerWhite))
+}
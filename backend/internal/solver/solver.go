@@ -0,0 +1,517 @@
+// Package solver plays small m,n,k games perfectly. A Solver searches the
+// whole game tree with alpha-beta pruning and remembers every position it
+// decides in a transposition table shared by all symmetric positions. The
+// table can be saved as a tablebase and loaded by the server, which then
+// plays and annotates games of the same configuration perfectly.
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+	"sync"
+
+	"webgames/internal/mnkgame"
+)
+
+// MaxCells is the largest board a Solver handles, both marks and the side
+// to move have to fit into a 64-bit key.
+const MaxCells = 30
+
+// lookupNodes bounds the search of Lookup and BestMove, which answer web
+// requests and fall back to the engine if the tablebase does not cover the
+// position well enough.
+const lookupNodes = 200_000
+
+// winScore is the score of a game won right now, wins further away score
+// one less per ply.
+const winScore = 100
+
+var ErrBudget = errors.New("search budget exhausted")
+
+// Config identifies the games a Solver can play.
+type Config struct {
+	Width  int
+	Height int
+	WinRow int
+	Rules  mnkgame.Rules
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("%dx%d k=%d %s", c.Width, c.Height, c.WinRow, c.Rules)
+}
+
+// ConfigOf returns the configuration of g, or an error if g is not a
+// two-sided, one stone per turn game on a small flat board with standard or
+// misère rules.
+func ConfigOf(g *mnkgame.Game) (Config, error) {
+	switch {
+	case g.Infinite || g.Geometry != mnkgame.GeometrySquare || g.Layers() > 1 || g.Topology != mnkgame.TopologyFlat:
+		return Config{}, errors.New("only flat square boards can be solved")
+	case g.Players != 2 || g.StonesPerTurn != 1 || g.FirstTurnStones != 1:
+		return Config{}, errors.New("only two sides placing one stone per turn can be solved")
+	case g.Variant != "":
+		return Config{}, errors.New("scripted variants cannot be solved")
+	case g.Rules != mnkgame.RulesStandard && g.Rules != mnkgame.RulesMisere:
+		return Config{}, fmt.Errorf("%s games cannot be solved", g.Rules)
+	}
+
+	c := Config{Width: g.Width, Height: g.Height, WinRow: g.WinRow, Rules: g.Rules}
+	return c, c.validate()
+}
+
+func (c Config) validate() error {
+	if c.Width < 1 || c.Height < 1 || c.Width*c.Height > MaxCells {
+		return fmt.Errorf("board %dx%d has more than %d cells", c.Width, c.Height, MaxCells)
+	}
+	if c.WinRow < 1 {
+		return fmt.Errorf("invalid win row %d", c.WinRow)
+	}
+	if c.Rules != mnkgame.RulesStandard && c.Rules != mnkgame.RulesMisere {
+		return fmt.Errorf("%s games cannot be solved", c.Rules)
+	}
+	return nil
+}
+
+// Outcome is the result of perfect play for the side to move.
+type Outcome int
+
+const (
+	OutcomeDraw Outcome = iota
+	OutcomeWin
+	OutcomeLoss
+)
+
+var outcomeName = map[Outcome]string{
+	OutcomeDraw: "Draw",
+	OutcomeWin:  "Win",
+	OutcomeLoss: "Loss",
+}
+
+func (o Outcome) String() string {
+	return outcomeName[o]
+}
+
+// Result is the value of a position under perfect play: the winning side
+// wins as soon as it can, the losing side holds out as long as it can.
+type Result struct {
+	Outcome Outcome
+	// Plies counts the stones until the decisive one, which is included.
+	Plies int
+}
+
+func resultOf(score int) Result {
+	switch {
+	case score > 0:
+		return Result{Outcome: OutcomeWin, Plies: winScore - score}
+	case score < 0:
+		return Result{Outcome: OutcomeLoss, Plies: winScore + score}
+	}
+	return Result{Outcome: OutcomeDraw}
+}
+
+// Moves counts the moves of the side to move until the game is decided.
+func (r Result) Moves() int {
+	return (r.Plies + 1) / 2
+}
+
+func (r Result) String() string {
+	if r.Outcome == OutcomeDraw {
+		return r.Outcome.String()
+	}
+	return fmt.Sprintf("%s in %d", r.Outcome, r.Plies)
+}
+
+// Bound tells how a stored score relates to the value of the position.
+type Bound uint8
+
+const (
+	BoundExact Bound = iota
+	BoundLower
+	BoundUpper
+)
+
+type entry struct {
+	// score is relative to the position: wins and losses count their
+	// plies from it.
+	score int8
+	bound Bound
+}
+
+// Solver searches games of one Config. It is safe for concurrent use.
+type Solver struct {
+	Config Config
+
+	mu    sync.Mutex
+	table map[uint64]entry
+	// cells is the number of cells, full has a bit for each of them.
+	cells int
+	full  uint32
+	// linesAt holds the winning lines through every cell.
+	linesAt [][]uint32
+	// order lists the cells busiest first, the moves to try first.
+	order []int
+	// symmetries are permutations of the cells mapping the board onto
+	// itself, the identity first.
+	symmetries [][]int
+
+	ctx      context.Context
+	nodes    int
+	maxNodes int
+}
+
+// New creates a Solver with an empty table.
+func New(c Config) (*Solver, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Solver{
+		Config:  c,
+		table:   map[uint64]entry{},
+		cells:   c.Width * c.Height,
+		linesAt: make([][]uint32, c.Width*c.Height),
+	}
+	s.full = uint32(1)<<s.cells - 1
+
+	for y := range c.Height {
+		for x := range c.Width {
+			for _, d := range []mnkgame.Position{{X: 1}, {Y: 1}, {X: 1, Y: 1}, {X: -1, Y: 1}} {
+				endX, endY := x+(c.WinRow-1)*d.X, y+(c.WinRow-1)*d.Y
+				if endX < 0 || endX >= c.Width || endY >= c.Height {
+					continue
+				}
+
+				var line uint32
+				for i := range c.WinRow {
+					line |= 1 << s.index(x+i*d.X, y+i*d.Y)
+				}
+				for i := range c.WinRow {
+					cell := s.index(x+i*d.X, y+i*d.Y)
+					s.linesAt[cell] = append(s.linesAt[cell], line)
+				}
+			}
+		}
+	}
+
+	for i := range s.cells {
+		s.order = append(s.order, i)
+	}
+	slices.SortStableFunc(s.order, func(a, b int) int {
+		return len(s.linesAt[b]) - len(s.linesAt[a])
+	})
+
+	s.symmetries = symmetries(c.Width, c.Height)
+
+	return s, nil
+}
+
+func (s *Solver) index(x, y int) int {
+	return y*s.Config.Width + x
+}
+
+// symmetries returns the cell permutations of the symmetries of a
+// width x height board: all 8 for a square, the flips for a rectangle.
+func symmetries(width, height int) [][]int {
+	var transforms []func(x, y int) (int, int)
+	if width == height {
+		for _, sym := range mnkgame.NewGame(width, height, 1).Symmetries() {
+			transforms = append(transforms, func(x, y int) (int, int) {
+				pos := sym.Apply(mnkgame.Position{X: x, Y: y}, width)
+				return pos.X, pos.Y
+			})
+		}
+	} else {
+		transforms = []func(x, y int) (int, int){
+			func(x, y int) (int, int) { return x, y },
+			func(x, y int) (int, int) { return width - 1 - x, y },
+			func(x, y int) (int, int) { return x, height - 1 - y },
+			func(x, y int) (int, int) { return width - 1 - x, height - 1 - y },
+		}
+	}
+
+	perms := make([][]int, len(transforms))
+	for i, t := range transforms {
+		perms[i] = make([]int, width*height)
+		for y := range height {
+			for x := range width {
+				tx, ty := t(x, y)
+				perms[i][y*width+x] = ty*width + tx
+			}
+		}
+	}
+
+	return perms
+}
+
+func permute(stones uint32, perm []int) uint32 {
+	var out uint32
+	for stones != 0 {
+		i := bits.TrailingZeros32(stones)
+		stones &= stones - 1
+		out |= 1 << perm[i]
+	}
+	return out
+}
+
+// key returns the smallest key among the symmetric images of the position.
+func (s *Solver) key(x, o uint32, xToMove bool) uint64 {
+	var turn uint64
+	if xToMove {
+		turn = 1 << 63
+	}
+
+	best := uint64(x) | uint64(o)<<32 | turn
+	for _, perm := range s.symmetries[1:] {
+		key := uint64(permute(x, perm)) | uint64(permute(o, perm))<<32 | turn
+		best = min(best, key)
+	}
+	return best
+}
+
+func (s *Solver) completes(stones uint32, cell int) bool {
+	stones |= 1 << cell
+	for _, line := range s.linesAt[cell] {
+		if stones&line == line {
+			return true
+		}
+	}
+	return false
+}
+
+// position returns the stones of the side to move and of the other side.
+func (s *Solver) position(g *mnkgame.Game) (mover, other uint32, xToMove bool, err error) {
+	c, err := ConfigOf(g)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if c != s.Config {
+		return 0, 0, false, fmt.Errorf("solver plays %s, not %s", s.Config, c)
+	}
+	if g.Status != mnkgame.StatusTurn {
+		return 0, 0, false, errors.New("game is not in progress")
+	}
+
+	var x, o uint32
+	for pos, cell := range g.Board {
+		switch cell {
+		case mnkgame.CellX:
+			x |= 1 << s.index(pos.X, pos.Y)
+		case mnkgame.CellO:
+			o |= 1 << s.index(pos.X, pos.Y)
+		}
+	}
+
+	if g.Turn == mnkgame.PlayerO {
+		return o, x, false, nil
+	}
+	return x, o, true, nil
+}
+
+// Solve returns the value of the position of g for the side to move,
+// searching as long as it takes unless ctx is cancelled.
+func (s *Solver) Solve(ctx context.Context, g *mnkgame.Game) (Result, error) {
+	mover, other, xToMove, err := s.position(g)
+	if err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ctx, s.nodes, s.maxNodes = ctx, 0, 0
+	score, err := s.search(mover, other, xToMove, 0, -winScore, winScore)
+	if err != nil {
+		return Result{}, err
+	}
+	return resultOf(score), nil
+}
+
+// Lookup returns the value of the position of g for the side to move if
+// the table decides it within a small search.
+func (s *Solver) Lookup(g *mnkgame.Game) (Result, bool) {
+	mover, other, xToMove, err := s.position(g)
+	if err != nil {
+		return Result{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ctx, s.nodes, s.maxNodes = context.Background(), 0, lookupNodes
+	score, err := s.search(mover, other, xToMove, 0, -winScore, winScore)
+	if err != nil {
+		return Result{}, false
+	}
+	return resultOf(score), true
+}
+
+// BestMove returns a perfect move for the side to move of g and the value
+// of the position, if the table decides them within a small search. The
+// move is the first one found to keep the value, which only needs a narrow
+// search around it.
+func (s *Solver) BestMove(g *mnkgame.Game) (mnkgame.Position, Result, bool) {
+	mover, other, xToMove, err := s.position(g)
+	if err != nil {
+		return mnkgame.Position{}, Result{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ctx, s.nodes, s.maxNodes = context.Background(), 0, lookupNodes
+	value, err := s.search(mover, other, xToMove, 0, -winScore, winScore)
+	if err != nil {
+		return mnkgame.Position{}, Result{}, false
+	}
+
+	empty := s.full &^ (mover | other)
+	for _, cell := range s.order {
+		if empty&(1<<cell) == 0 {
+			continue
+		}
+
+		score, err := s.play(mover, other, xToMove, cell, 0, value-1, value+1)
+		if err != nil {
+			return mnkgame.Position{}, Result{}, false
+		}
+		if score >= value {
+			pos := mnkgame.Position{X: cell % s.Config.Width, Y: cell / s.Config.Width}
+			return pos, resultOf(value), true
+		}
+	}
+
+	return mnkgame.Position{}, Result{}, false
+}
+
+// play returns the score for the mover of placing a stone on cell, a
+// position ply stones away from the root.
+func (s *Solver) play(mover, other uint32, xToMove bool, cell, ply, alpha, beta int) (int, error) {
+	if s.completes(mover, cell) {
+		if s.Config.Rules == mnkgame.RulesMisere {
+			return -(winScore - ply - 1), nil
+		}
+		return winScore - ply - 1, nil
+	}
+
+	mover |= 1 << cell
+	if mover|other == s.full {
+		return 0, nil
+	}
+
+	score, err := s.search(other, mover, !xToMove, ply+1, -beta, -alpha)
+	return -score, err
+}
+
+// search returns the score of the position for the side to move, exact if
+// it lies between alpha and beta and a bound beyond the one passed
+// otherwise.
+func (s *Solver) search(mover, other uint32, xToMove bool, ply, alpha, beta int) (int, error) {
+	s.nodes++
+	if s.maxNodes > 0 && s.nodes > s.maxNodes {
+		return 0, ErrBudget
+	}
+	if s.nodes%4096 == 0 {
+		if err := s.ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	empty := s.full &^ (mover | other)
+	if empty == 0 {
+		return 0, nil
+	}
+
+	x, o := mover, other
+	if !xToMove {
+		x, o = other, mover
+	}
+	key := s.key(x, o, xToMove)
+
+	if e, ok := s.table[key]; ok {
+		score := fromTable(int(e.score), ply)
+		switch e.bound {
+		case BoundExact:
+			return score, nil
+		case BoundLower:
+			alpha = max(alpha, score)
+		case BoundUpper:
+			beta = min(beta, score)
+		}
+		if alpha >= beta {
+			return score, nil
+		}
+	}
+
+	// a line right away is the best there is
+	if s.Config.Rules == mnkgame.RulesStandard {
+		for _, cell := range s.order {
+			if empty&(1<<cell) != 0 && s.completes(mover, cell) {
+				score := winScore - ply - 1
+				s.table[key] = entry{score: int8(toTable(score, ply)), bound: BoundExact}
+				return score, nil
+			}
+		}
+	}
+
+	origAlpha, best := alpha, -winScore-1
+	for _, cell := range s.order {
+		if empty&(1<<cell) == 0 {
+			continue
+		}
+
+		score, err := s.play(mover, other, xToMove, cell, ply, alpha, beta)
+		if err != nil {
+			return 0, err
+		}
+
+		best = max(best, score)
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	bound := BoundExact
+	switch {
+	case best <= origAlpha:
+		bound = BoundUpper
+	case best >= beta:
+		bound = BoundLower
+	}
+	s.table[key] = entry{score: int8(toTable(best, ply)), bound: bound}
+
+	return best, nil
+}
+
+// toTable makes a score relative to the root relative to the position ply
+// stones away from it, fromTable turns it back.
+func toTable(score, ply int) int {
+	switch {
+	case score > 0:
+		return score + ply
+	case score < 0:
+		return score - ply
+	}
+	return 0
+}
+
+func fromTable(score, ply int) int {
+	switch {
+	case score > 0:
+		return score - ply
+	case score < 0:
+		return score + ply
+	}
+	return 0
+}
+
+// Positions returns the number of positions in the table.
+func (s *Solver) Positions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.table)
+}
@@ -0,0 +1,160 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"webgames/internal/mnkgame"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func solve(t *testing.T, g *mnkgame.Game) (*Solver, Result) {
+	t.Helper()
+
+	c, err := ConfigOf(g)
+	require.Nil(t, err)
+	s, err := New(c)
+	require.Nil(t, err)
+
+	result, err := s.Solve(context.Background(), g)
+	require.Nil(t, err)
+	return s, result
+}
+
+func play(t *testing.T, g *mnkgame.Game, moves ...mnkgame.Position) {
+	t.Helper()
+	for _, pos := range moves {
+		require.Nil(t, mnkgame.MakeTurn(g, pos))
+	}
+}
+
+func TestKnownValues(t *testing.T) {
+	tests := []struct {
+		width, height, winRow int
+		want                  Outcome
+	}{
+		{3, 3, 3, OutcomeDraw},
+		{4, 3, 3, OutcomeWin},
+		{4, 4, 3, OutcomeWin},
+		{4, 4, 4, OutcomeDraw},
+	}
+
+	for _, tt := range tests {
+		_, result := solve(t, mnkgame.NewGame(tt.width, tt.height, tt.winRow))
+		assert.Equal(t, tt.want, result.Outcome, "%dx%d k=%d", tt.width, tt.height, tt.winRow)
+	}
+}
+
+func TestWinDistance(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	// X has two in a row with the third cell free
+	play(t, g,
+		mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 0, Y: 1},
+		mnkgame.Position{X: 1, Y: 0}, mnkgame.Position{X: 1, Y: 1},
+	)
+	_, result := solve(t, g)
+	assert.Equal(t, Result{Outcome: OutcomeWin, Plies: 1}, result)
+
+	// O blocks, then X forks
+	g = mnkgame.NewGame(3, 3, 3)
+	play(t, g, mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 1, Y: 0})
+	_, result = solve(t, g)
+	assert.Equal(t, OutcomeWin, result.Outcome)
+	assert.Equal(t, 5, result.Plies)
+	assert.Equal(t, 3, result.Moves())
+	assert.Equal(t, "Win in 5", result.String())
+}
+
+func TestMisere(t *testing.T) {
+	g := mnkgame.NewGame(3, 3, 3)
+	g.Rules = mnkgame.RulesMisere
+	// X opening in the centre and mirroring O never completes a line first
+	_, result := solve(t, g)
+	assert.Equal(t, OutcomeDraw, result.Outcome)
+
+	// the only free cell completes X's line
+	g = mnkgame.NewGame(3, 3, 3)
+	g.Rules = mnkgame.RulesMisere
+	play(t, g,
+		mnkgame.Position{X: 0, Y: 0}, mnkgame.Position{X: 1, Y: 0},
+		mnkgame.Position{X: 2, Y: 0}, mnkgame.Position{X: 0, Y: 1},
+		mnkgame.Position{X: 1, Y: 1}, mnkgame.Position{X: 2, Y: 1},
+		mnkgame.Position{X: 1, Y: 2}, mnkgame.Position{X: 0, Y: 2},
+	)
+	_, result = solve(t, g)
+	assert.Equal(t, Result{Outcome: OutcomeLoss, Plies: 1}, result)
+}
+
+func TestSymmetricPositionsShareEntries(t *testing.T) {
+	s, _ := solve(t, mnkgame.NewGame(3, 3, 3))
+
+	// 765 positions of tic-tac-toe are distinct up to symmetry, the table
+	// holds those that are not decided by the move leading to them
+	assert.LessOrEqual(t, s.Positions(), 765)
+
+	for _, corner := range []mnkgame.Position{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 2}, {X: 2, Y: 2}} {
+		g := mnkgame.NewGame(3, 3, 3)
+		play(t, g, corner)
+		result, ok := s.Lookup(g)
+		require.True(t, ok)
+		assert.Equal(t, OutcomeDraw, result.Outcome)
+	}
+}
+
+func TestBestMovePlaysPerfectly(t *testing.T) {
+	g := mnkgame.NewGame(4, 4, 3)
+	s, want := solve(t, g)
+
+	// the solver playing both sides keeps the value of the opening
+	for g.Status == mnkgame.StatusTurn {
+		pos, result, ok := s.BestMove(g)
+		require.True(t, ok)
+		assert.Equal(t, want, result)
+
+		require.Nil(t, mnkgame.MakeTurn(g, pos))
+		want = Result{Outcome: OutcomeWin + OutcomeLoss - want.Outcome, Plies: want.Plies - 1}
+	}
+
+	assert.Equal(t, mnkgame.StatusWin, g.Status)
+	assert.Equal(t, mnkgame.PlayerX, g.Winner)
+}
+
+func TestTablebaseRoundTrip(t *testing.T) {
+	s, _ := solve(t, mnkgame.NewGame(4, 3, 3))
+
+	var buf bytes.Buffer
+	n, err := s.WriteTo(&buf)
+	require.Nil(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	loaded, err := ReadTablebase(&buf)
+	require.Nil(t, err)
+	assert.Equal(t, s.Config, loaded.Config)
+	assert.Equal(t, s.table, loaded.table)
+
+	_, err = ReadTablebase(bytes.NewBufferString("not a tablebase"))
+	assert.NotNil(t, err)
+}
+
+func TestConfigOfRejectsUnsolvableGames(t *testing.T) {
+	for _, g := range []*mnkgame.Game{
+		mnkgame.NewGame(7, 7, 5),
+		mnkgame.NewPenteGame(5, 5),
+		mnkgame.NewInfiniteGame(3),
+		mnkgame.NewConnectGame(5, 5, 4, 2, 1),
+	} {
+		_, err := ConfigOf(g)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestLookupBudget(t *testing.T) {
+	s, err := New(Config{Width: 5, Height: 5, WinRow: 4})
+	require.Nil(t, err)
+
+	_, ok := s.Lookup(mnkgame.NewGame(5, 5, 4))
+	assert.False(t, ok)
+}
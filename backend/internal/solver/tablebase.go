@@ -0,0 +1,167 @@
+package solver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"webgames/internal/mnkgame"
+)
+
+// A tablebase file starts with tablebaseMagic and the Config as uvarints,
+// followed by the number of positions and the positions sorted by key. Each
+// position is the difference of its key to the previous one as a uvarint,
+// then its score and bound as one byte each.
+const tablebaseMagic = "MNKTB1"
+
+// WriteTo writes the table as a tablebase.
+func (s *Solver) WriteTo(w io.Writer) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]uint64, 0, len(s.table))
+	for key := range s.table {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	cw.Write([]byte(tablebaseMagic))
+	for _, v := range []int{s.Config.Width, s.Config.Height, s.Config.WinRow, int(s.Config.Rules), len(keys)} {
+		cw.Write(binary.AppendUvarint(nil, uint64(v)))
+	}
+
+	var prev uint64
+	for _, key := range keys {
+		e := s.table[key]
+		buf := binary.AppendUvarint(nil, key-prev)
+		cw.Write(append(buf, byte(e.score), byte(e.bound)))
+		prev = key
+	}
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) {
+	if c.err != nil {
+		return
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+}
+
+// ReadTablebase creates a Solver from a tablebase written by WriteTo.
+func ReadTablebase(r io.Reader) (*Solver, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(tablebaseMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != tablebaseMagic {
+		return nil, errors.New("not a tablebase")
+	}
+
+	var header [5]uint64
+	for i := range header {
+		v, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("read header: %w", err)
+		}
+		header[i] = v
+	}
+
+	s, err := New(Config{
+		Width:  int(header[0]),
+		Height: int(header[1]),
+		WinRow: int(header[2]),
+		Rules:  mnkgame.Rules(header[3]),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the count is only trusted as far as it is cheap
+	s.table = make(map[uint64]entry, min(header[4], 1<<26))
+
+	var key uint64
+	for range header[4] {
+		delta, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("read position: %w", err)
+		}
+		var value [2]byte
+		if _, err := io.ReadFull(br, value[:]); err != nil {
+			return nil, fmt.Errorf("read position: %w", err)
+		}
+
+		key += delta
+		s.table[key] = entry{score: int8(value[0]), bound: Bound(value[1])}
+	}
+
+	return s, nil
+}
+
+// LoadDir reads the *.tb tablebases of dir. A missing dir holds none.
+func LoadDir(dir string) ([]*Solver, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tb"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+
+	var solvers []*Solver
+	for _, path := range paths {
+		s, err := readFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		solvers = append(solvers, s)
+	}
+
+	return solvers, nil
+}
+
+func readFile(path string) (*Solver, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadTablebase(f)
+}
+
+var registry = map[Config]*Solver{}
+
+// Register makes s the solver consulted for games of its Config. It panics
+// if the Config is taken, as registration happens once at startup.
+func Register(s *Solver) {
+	if _, ok := registry[s.Config]; ok {
+		panic(fmt.Sprintf("solver: tablebase for %s registered twice", s.Config))
+	}
+	registry[s.Config] = s
+}
+
+// For returns the registered solver for the configuration of g.
+func For(g *mnkgame.Game) (*Solver, bool) {
+	c, err := ConfigOf(g)
+	if err != nil {
+		return nil, false
+	}
+
+	s, ok := registry[c]
+	return s, ok
+}
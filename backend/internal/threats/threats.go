@@ -0,0 +1,375 @@
+// Package threats finds forced wins in m,n,k games the way gomoku players
+// look for them. A VCF (victory by continuous fours) wins with moves that
+// each threaten to complete a line right away, so every reply is forced. A
+// VCT (victory by continuous threats) may also play moves that threaten a
+// VCF, where the defender has a few replies worth trying.
+//
+// Threats are counted on the windows of mnkgame.Game.Lines: a window holding
+// WinRow-1 stones of a side and no other stone is a four, one holding
+// WinRow-2 is a three.
+package threats
+
+import (
+	"errors"
+	"fmt"
+
+	"webgames/internal/mnkgame"
+)
+
+const (
+	// MaxVCFDepth bounds the number of fours of a VCF.
+	MaxVCFDepth = 15
+	// MaxVCTDepth bounds the number of threats of a VCT before its final VCF.
+	MaxVCTDepth = 3
+	// maxNodes bounds a search, which then reports no win.
+	maxNodes = 200_000
+)
+
+// Kind tells how a forced win is made.
+type Kind int
+
+const (
+	KindVCF Kind = iota
+	KindVCT
+)
+
+var kindName = map[Kind]string{
+	KindVCF: "VCF",
+	KindVCT: "VCT",
+}
+
+func (k Kind) String() string {
+	return kindName[k]
+}
+
+// Win is a forced win of the side to move.
+type Win struct {
+	Kind     Kind
+	Attacker mnkgame.Player
+	// Moves alternate between the attacker and the defender. They start
+	// with the attacker's first threat and end with the stone completing
+	// the line; the defender's replies are the most stubborn ones found.
+	Moves []mnkgame.Position
+}
+
+// Check returns an error if threats of g cannot be searched: only games of
+// two sides placing one stone per turn, where any line of WinRow or more
+// wins, are supported.
+func Check(g *mnkgame.Game) error {
+	switch {
+	case g.Players != 2 || g.StonesPerTurn != 1 || g.FirstTurnStones != 1:
+		return errors.New("only two sides placing one stone per turn are supported")
+	case g.Rules != mnkgame.RulesStandard:
+		return fmt.Errorf("%s games are not supported", g.Rules)
+	case g.Variant != "":
+		return errors.New("scripted variants are not supported")
+	case g.Status != mnkgame.StatusTurn:
+		return errors.New("game is not in progress")
+	}
+	return nil
+}
+
+// FindVCF returns a VCF of the side to move of g.
+func FindVCF(g *mnkgame.Game) (Win, bool) {
+	if Check(g) != nil {
+		return Win{}, false
+	}
+
+	b := newBoard(g)
+	att, def := g.Turn.Cell(), other(g.Turn.Cell())
+	cells, ok := b.vcf(att, def, MaxVCFDepth)
+	if !ok {
+		return Win{}, false
+	}
+	return Win{Kind: KindVCF, Attacker: g.Turn, Moves: b.positions(cells)}, true
+}
+
+// Live reports whether side p of g has a three or a four, a line a stone or
+// two short of WinRow with no stone of the other side. Without one neither
+// side's forced wins are worth searching for in play.
+func Live(g *mnkgame.Game, p mnkgame.Player) bool {
+	if Check(g) != nil {
+		return false
+	}
+
+	b := newBoard(g)
+	return len(b.winning(p.Cell())) > 0 || len(b.fours(p.Cell())) > 0
+}
+
+// FindVCT returns a VCF of the side to move of g or, failing that, a VCT.
+func FindVCT(g *mnkgame.Game) (Win, bool) {
+	if win, ok := FindVCF(g); ok {
+		return win, true
+	}
+	if Check(g) != nil {
+		return Win{}, false
+	}
+
+	b := newBoard(g)
+	att, def := g.Turn.Cell(), other(g.Turn.Cell())
+	cells, ok := b.vct(att, def, MaxVCTDepth)
+	if !ok {
+		return Win{}, false
+	}
+	return Win{Kind: KindVCT, Attacker: g.Turn, Moves: b.positions(cells)}, true
+}
+
+// board numbers the cells and windows of a game and counts the stones of
+// both marks in every window as stones come and go.
+type board struct {
+	winRow  int
+	cells   []mnkgame.Position
+	stones  []mnkgame.Cell
+	lines   [][]int
+	linesAt [][]int
+	count   map[mnkgame.Cell][]int
+
+	// seen marks cells collected by the current call of collect
+	seen  []int
+	stamp int
+	nodes int
+}
+
+func newBoard(g *mnkgame.Game) *board {
+	b := &board{
+		winRow: g.WinRow,
+		count:  map[mnkgame.Cell][]int{},
+	}
+
+	// on an infinite board the search may play a few stones beyond the
+	// current ones, their windows have to be there too
+	margin := 0
+	if g.Infinite {
+		margin = 2 * g.WinRow
+	}
+
+	index := map[mnkgame.Position]int{}
+	for _, line := range g.LinesAround(margin) {
+		var cells []int
+		for _, pos := range line {
+			i, ok := index[pos]
+			if !ok {
+				i = len(b.cells)
+				index[pos] = i
+				b.cells = append(b.cells, pos)
+				b.stones = append(b.stones, mnkgame.CellEmpty)
+				b.linesAt = append(b.linesAt, nil)
+			}
+			cells = append(cells, i)
+			b.linesAt[i] = append(b.linesAt[i], len(b.lines))
+		}
+		b.lines = append(b.lines, cells)
+	}
+
+	b.seen = make([]int, len(b.cells))
+	for _, cell := range []mnkgame.Cell{mnkgame.CellX, mnkgame.CellO} {
+		b.count[cell] = make([]int, len(b.lines))
+	}
+	for pos, cell := range g.Board {
+		if i, ok := index[pos]; ok {
+			b.place(i, cell)
+		}
+	}
+
+	return b
+}
+
+func other(cell mnkgame.Cell) mnkgame.Cell {
+	if cell == mnkgame.CellX {
+		return mnkgame.CellO
+	}
+	return mnkgame.CellX
+}
+
+func (b *board) place(i int, cell mnkgame.Cell) {
+	b.stones[i] = cell
+	for _, l := range b.linesAt[i] {
+		b.count[cell][l]++
+	}
+}
+
+func (b *board) remove(i int) {
+	cell := b.stones[i]
+	b.stones[i] = mnkgame.CellEmpty
+	for _, l := range b.linesAt[i] {
+		b.count[cell][l]--
+	}
+}
+
+func (b *board) positions(cells []int) []mnkgame.Position {
+	positions := make([]mnkgame.Position, len(cells))
+	for i, cell := range cells {
+		positions[i] = b.cells[cell]
+	}
+	return positions
+}
+
+// collect returns the empty cells of the windows holding have stones of
+// cell and none of the other mark, each once: with have = WinRow-1 the
+// cells completing a line, with WinRow-2 the cells making a four.
+func (b *board) collect(cell mnkgame.Cell, have int) []int {
+	b.stamp++
+
+	var cells []int
+	own, others := b.count[cell], b.count[other(cell)]
+	for l, line := range b.lines {
+		if own[l] != have || others[l] != 0 {
+			continue
+		}
+
+		for _, i := range line {
+			if b.stones[i] == mnkgame.CellEmpty && b.seen[i] != b.stamp {
+				b.seen[i] = b.stamp
+				cells = append(cells, i)
+			}
+		}
+	}
+
+	return cells
+}
+
+func (b *board) winning(cell mnkgame.Cell) []int {
+	return b.collect(cell, b.winRow-1)
+}
+
+func (b *board) fours(cell mnkgame.Cell) []int {
+	return b.collect(cell, b.winRow-2)
+}
+
+// threes returns the cells making a three, none on boards where a three
+// would be a lone stone.
+func (b *board) threes(cell mnkgame.Cell) []int {
+	if b.winRow-3 < 1 {
+		return nil
+	}
+	return b.collect(cell, b.winRow-3)
+}
+
+// budget counts a node and reports whether the search may go on.
+func (b *board) budget() bool {
+	b.nodes++
+	return b.nodes <= maxNodes
+}
+
+// vcf returns the moves of a VCF of att with att to move.
+func (b *board) vcf(att, def mnkgame.Cell, depth int) ([]int, bool) {
+	if !b.budget() {
+		return nil, false
+	}
+
+	if wins := b.winning(att); len(wins) > 0 {
+		return []int{wins[0]}, true
+	}
+	// a four of the defender has to be blocked, which is no four
+	if len(b.winning(def)) > 0 || depth == 0 {
+		return nil, false
+	}
+
+	for _, m := range b.fours(att) {
+		b.place(m, att)
+		wins := b.winning(att)
+
+		if len(wins) >= 2 {
+			b.remove(m)
+			return []int{m, wins[0], wins[1]}, true
+		}
+
+		block := wins[0]
+		b.place(block, def)
+		rest, ok := b.vcf(att, def, depth-1)
+		b.remove(block)
+		b.remove(m)
+
+		if ok {
+			return append([]int{m, block}, rest...), true
+		}
+	}
+
+	return nil, false
+}
+
+// vct returns the moves of a VCT of att with att to move. A three only
+// counts if att would have a VCF after it; the defences tried are the cells
+// of that VCF and the cells giving the defender a three or four of its
+// own, which covers the defences of a threat in practice.
+func (b *board) vct(att, def mnkgame.Cell, depth int) ([]int, bool) {
+	if !b.budget() {
+		return nil, false
+	}
+
+	if moves, ok := b.vcf(att, def, MaxVCFDepth); ok {
+		return moves, true
+	}
+	if len(b.winning(def)) > 0 || depth == 0 {
+		return nil, false
+	}
+
+	fours := b.fours(att)
+	for _, m := range fours {
+		b.place(m, att)
+		block := b.winning(att)[0]
+		b.place(block, def)
+		rest, ok := b.vct(att, def, depth-1)
+		b.remove(block)
+		b.remove(m)
+
+		if ok {
+			return append([]int{m, block}, rest...), true
+		}
+	}
+
+	isFour := map[int]bool{}
+	for _, m := range fours {
+		isFour[m] = true
+	}
+
+	for _, m := range b.threes(att) {
+		if isFour[m] {
+			continue
+		}
+
+		b.place(m, att)
+		if moves, ok := b.defend(att, def, depth); ok {
+			b.remove(m)
+			return append([]int{m}, moves...), true
+		}
+		b.remove(m)
+	}
+
+	return nil, false
+}
+
+// defend tries the defences against the threat att just made and returns
+// the most stubborn one and the win after it if none holds.
+func (b *board) defend(att, def mnkgame.Cell, depth int) ([]int, bool) {
+	follow, ok := b.vcf(att, def, MaxVCFDepth)
+	if !ok {
+		return nil, false
+	}
+
+	defences := append([]int{}, follow...)
+	defences = append(defences, b.fours(def)...)
+	defences = append(defences, b.threes(def)...)
+
+	var worst []int
+	tried := map[int]bool{}
+	for _, d := range defences {
+		if tried[d] || b.stones[d] != mnkgame.CellEmpty {
+			continue
+		}
+		tried[d] = true
+
+		b.place(d, def)
+		rest, ok := b.vct(att, def, depth-1)
+		b.remove(d)
+
+		if !ok {
+			return nil, false
+		}
+		if worst == nil || len(rest)+1 > len(worst) {
+			worst = append([]int{d}, rest...)
+		}
+	}
+
+	return worst, true
+}
@@ -0,0 +1,136 @@
+package threats
+
+import (
+	"testing"
+
+	"webgames/internal/mnkgame"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setup returns a gomoku position with X to move.
+func setup(g *mnkgame.Game, x, o []mnkgame.Position) *mnkgame.Game {
+	for _, pos := range x {
+		g.Board[pos] = mnkgame.CellX
+	}
+	for _, pos := range o {
+		g.Board[pos] = mnkgame.CellO
+	}
+	g.Turn = mnkgame.PlayerX
+	g.Hash = g.Board.Hash()
+	return g
+}
+
+// farO keeps the stone counts even without touching the fight.
+var farO = []mnkgame.Position{{X: 0, Y: 0}, {X: 14, Y: 14}, {X: 0, Y: 14}, {X: 14, Y: 0}}
+
+// replay plays the moves of win and requires them to end the game with a
+// win of the attacker.
+func replay(t *testing.T, g *mnkgame.Game, win Win) {
+	t.Helper()
+
+	g = g.Clone()
+	for i, pos := range win.Moves {
+		require.Equal(t, mnkgame.StatusTurn, g.Status, "move %d", i)
+		require.Nil(t, mnkgame.MakeTurn(g, pos), "move %d", i)
+	}
+	assert.Equal(t, mnkgame.StatusWin, g.Status)
+	assert.Equal(t, win.Attacker, g.Winner)
+}
+
+func TestVCFThroughClosedFours(t *testing.T) {
+	// every four of X is blocked on one side, only the last move makes two
+	g := setup(mnkgame.NewGame(15, 15, 5),
+		[]mnkgame.Position{{X: 5, Y: 7}, {X: 6, Y: 7}, {X: 7, Y: 7}, {X: 9, Y: 5}, {X: 9, Y: 4}, {X: 9, Y: 3}},
+		[]mnkgame.Position{{X: 4, Y: 7}, {X: 9, Y: 2}, {X: 0, Y: 0}, {X: 14, Y: 14}, {X: 0, Y: 14}, {X: 14, Y: 0}},
+	)
+
+	win, ok := FindVCF(g)
+	require.True(t, ok)
+	assert.Equal(t, KindVCF, win.Kind)
+	assert.Equal(t, mnkgame.PlayerX, win.Attacker)
+	replay(t, g, win)
+
+	// every attacker move before the last threatens to win at once
+	c := g.Clone()
+	for i, pos := range win.Moves[:len(win.Moves)-1] {
+		require.Nil(t, mnkgame.MakeTurn(c, pos))
+		if i%2 == 0 {
+			b := newBoard(c)
+			assert.NotEmpty(t, b.winning(mnkgame.CellX), "move %d", i)
+		}
+	}
+}
+
+func TestDoubleThreeIsVCT(t *testing.T) {
+	g := setup(mnkgame.NewGame(15, 15, 5),
+		[]mnkgame.Position{{X: 6, Y: 7}, {X: 7, Y: 7}, {X: 9, Y: 5}, {X: 9, Y: 6}},
+		farO,
+	)
+
+	_, ok := FindVCF(g)
+	assert.False(t, ok)
+
+	win, ok := FindVCT(g)
+	require.True(t, ok)
+	assert.Equal(t, KindVCT, win.Kind)
+	replay(t, g, win)
+}
+
+func TestVCTOnInfiniteBoard(t *testing.T) {
+	g := setup(mnkgame.NewInfiniteGame(5),
+		[]mnkgame.Position{{X: 6, Y: 7}, {X: 7, Y: 7}, {X: 9, Y: 5}, {X: 9, Y: 6}},
+		farO,
+	)
+
+	win, ok := FindVCT(g)
+	require.True(t, ok)
+	replay(t, g, win)
+}
+
+func TestDefenderFourStopsVCF(t *testing.T) {
+	g := setup(mnkgame.NewGame(15, 15, 5),
+		[]mnkgame.Position{{X: 5, Y: 7}, {X: 6, Y: 7}, {X: 7, Y: 7}},
+		[]mnkgame.Position{{X: 2, Y: 12}, {X: 3, Y: 12}, {X: 4, Y: 12}, {X: 5, Y: 12}},
+	)
+
+	_, ok := FindVCF(g)
+	assert.False(t, ok)
+	_, ok = FindVCT(g)
+	assert.False(t, ok)
+}
+
+func TestNoWinInQuietPosition(t *testing.T) {
+	g := mnkgame.NewGame(15, 15, 5)
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 7, Y: 7}))
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 8, Y: 8}))
+
+	_, ok := FindVCT(g)
+	assert.False(t, ok)
+}
+
+func TestLive(t *testing.T) {
+	g := setup(mnkgame.NewGame(15, 15, 5),
+		[]mnkgame.Position{{X: 6, Y: 7}, {X: 7, Y: 7}},
+		[]mnkgame.Position{{X: 0, Y: 0}, {X: 14, Y: 14}},
+	)
+	assert.False(t, Live(g, mnkgame.PlayerX), "two stones are no threat")
+
+	g.Board[mnkgame.Position{X: 8, Y: 7}] = mnkgame.CellX
+	assert.True(t, Live(g, mnkgame.PlayerX))
+	assert.False(t, Live(g, mnkgame.PlayerO))
+
+	g = setup(mnkgame.NewGame(15, 15, 5),
+		[]mnkgame.Position{{X: 7, Y: 7}, {X: 0, Y: 14}, {X: 14, Y: 14}, {X: 14, Y: 0}},
+		[]mnkgame.Position{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}},
+	)
+	assert.True(t, Live(g, mnkgame.PlayerO), "a four is live as well")
+}
+
+func TestCheckRejectsOtherRules(t *testing.T) {
+	assert.NotNil(t, Check(mnkgame.NewPenteGame(15, 15)))
+	assert.NotNil(t, Check(mnkgame.NewConnectGame(19, 19, 6, 2, 1)))
+	assert.NotNil(t, Check(mnkgame.NewMultiplayerGame(15, 15, 5, 3, mnkgame.ScoringFirstToK)))
+	assert.Nil(t, Check(mnkgame.NewGame(15, 15, 5)))
+}
@@ -0,0 +1,267 @@
+package ultimate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type GameID string
+type PlayerID string
+
+// Game is a game of Ultimate tic-tac-toe: a 3x3 meta-board of 3x3 sub-boards.
+// Cells are addressed by their Position on the combined 9x9 grid.
+type Game struct {
+	ID    GameID
+	Seats []PlayerID
+	Board [9][9]Cell
+	// Meta holds the result of every sub-board in row-major order.
+	Meta [9]Cell
+	// Next is the sub-board the side to move has to play in, or -1 if the
+	// player is free to choose any open sub-board.
+	Next    int
+	Status  Status
+	Turn    Player
+	Winner  Player
+	History []Position
+}
+
+type Player int
+
+const (
+	PlayerX Player = iota + 1
+	PlayerO
+)
+
+func (p Player) Cell() Cell {
+	return Cell(p)
+}
+
+func (p Player) String() string {
+	return p.Cell().String()
+}
+
+type Cell int
+
+const (
+	CellEmpty Cell = iota
+	CellX
+	CellO
+	// CellDraw marks a sub-board that filled up without a line.
+	CellDraw
+)
+
+func (c Cell) String() string {
+	switch c {
+	case CellEmpty:
+		return " "
+	case CellX:
+		return "X"
+	case CellO:
+		return "O"
+	case CellDraw:
+		return "-"
+	}
+
+	return ""
+}
+
+type Status int
+
+const (
+	StatusOpponent Status = iota
+	StatusTurn
+	StatusWin
+	StatusDraw
+)
+
+var statusName = map[Status]string{
+	StatusOpponent: "Need opponent",
+	StatusTurn:     "Turn",
+	StatusWin:      "Win",
+	StatusDraw:     "Draw",
+}
+
+func (s Status) String() string {
+	return statusName[s]
+}
+
+func (g *Game) StatusText() string {
+	switch g.Status {
+	case StatusTurn:
+		return fmt.Sprintf("%s %s", g.Status, g.Turn)
+	case StatusWin:
+		return fmt.Sprintf("%s %s", g.Status, g.Winner)
+	}
+
+	return g.Status.String()
+}
+
+// PlayerID returns the player seated as the given side.
+func (g *Game) PlayerID(p Player) PlayerID {
+	if p < PlayerX || int(p) > len(g.Seats) {
+		return ""
+	}
+
+	return g.Seats[p-1]
+}
+
+type Position struct {
+	X int
+	Y int
+}
+
+// SubBoard returns the index of the sub-board containing pos.
+func (p Position) SubBoard() int {
+	return p.Y/3*3 + p.X/3
+}
+
+// SubCell returns the index of pos inside its sub-board, which is also the
+// sub-board the opponent is sent to.
+func (p Position) SubCell() int {
+	return p.Y%3*3 + p.X%3
+}
+
+type CreateGameParams struct {
+	PlayerID string
+}
+
+var gamesRepository = map[GameID]*Game{}
+
+func CreateGame(params CreateGameParams) *Game {
+	game := NewGame()
+	game.Seats = []PlayerID{PlayerID(params.PlayerID)}
+	game.Status = StatusOpponent
+	game.Turn = 0
+
+	gamesRepository[game.ID] = game
+	return game
+}
+
+func NewGame() *Game {
+	return &Game{
+		ID:     GameID(uuid.NewString()),
+		Next:   -1,
+		Status: StatusTurn,
+		Turn:   PlayerX,
+	}
+}
+
+func FindGame(id GameID) (*Game, bool) {
+	game, ok := gamesRepository[id]
+	return game, ok
+}
+
+func BecomeOpponent(game *Game, playerID PlayerID) error {
+	if game.Status != StatusOpponent {
+		return fmt.Errorf("invalid game status: %s", game.Status)
+	}
+
+	if slices.Contains(game.Seats, playerID) {
+		return errors.New("player has already joined the game")
+	}
+
+	game.Seats = append(game.Seats, playerID)
+	game.Status = StatusTurn
+	game.Turn = PlayerX
+
+	return nil
+}
+
+// Playable reports whether the side to move may play the given sub-board.
+func (g *Game) Playable(board int) bool {
+	if g.Status != StatusTurn || g.Meta[board] != CellEmpty {
+		return false
+	}
+
+	return g.Next == -1 || g.Next == board
+}
+
+// LegalMoves returns the empty cells of the sub-boards the side to move may
+// play, in row-major order of the combined grid.
+func (g *Game) LegalMoves() []Position {
+	var moves []Position
+	for y := range 9 {
+		for x := range 9 {
+			pos := Position{X: x, Y: y}
+			if g.Playable(pos.SubBoard()) && g.Board[pos.SubBoard()][pos.SubCell()] == CellEmpty {
+				moves = append(moves, pos)
+			}
+		}
+	}
+
+	return moves
+}
+
+func MakeTurn(g *Game, pos Position) error {
+	if pos.X < 0 || pos.X >= 9 || pos.Y < 0 || pos.Y >= 9 ||
+		g.Board[pos.SubBoard()][pos.SubCell()] != CellEmpty {
+		return errors.New("Position is out of bounds or cell is already occupied")
+	}
+
+	if g.Status != StatusTurn {
+		return fmt.Errorf("Game has already ended with status: %s", g.Status)
+	}
+
+	board := pos.SubBoard()
+	if !g.Playable(board) {
+		return fmt.Errorf("Sub-board %d cannot be played, the move has to go to sub-board %d", board, g.Next)
+	}
+
+	cell := g.Turn.Cell()
+	g.Board[board][pos.SubCell()] = cell
+	g.History = append(g.History, pos)
+
+	// settle the sub-board
+	if hasLine(g.Board[board], cell) {
+		g.Meta[board] = cell
+	} else if !slices.Contains(g.Board[board][:], CellEmpty) {
+		g.Meta[board] = CellDraw
+	}
+
+	if hasLine(g.Meta, cell) {
+		g.Status = StatusWin
+		g.Winner = g.Turn
+		return nil
+	}
+
+	if !slices.Contains(g.Meta[:], CellEmpty) {
+		g.Status = StatusDraw
+		return nil
+	}
+
+	// the opponent is sent to the sub-board matching the cell just played,
+	// unless that board is already decided
+	g.Next = pos.SubCell()
+	if g.Meta[g.Next] != CellEmpty {
+		g.Next = -1
+	}
+
+	if g.Turn == PlayerX {
+		g.Turn = PlayerO
+	} else {
+		g.Turn = PlayerX
+	}
+
+	return nil
+}
+
+var lines = [8][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// hasLine reports whether cell owns a full row, column or diagonal of a 3x3
+// board stored in row-major order.
+func hasLine(board [9]Cell, cell Cell) bool {
+	for _, line := range lines {
+		if board[line[0]] == cell && board[line[1]] == cell && board[line[2]] == cell {
+			return true
+		}
+	}
+
+	return false
+}
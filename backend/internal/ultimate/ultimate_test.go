@@ -0,0 +1,103 @@
+package ultimate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// at returns the grid position of cell c in sub-board b.
+func at(b, c int) Position {
+	return Position{X: b%3*3 + c%3, Y: b/3*3 + c/3}
+}
+
+func TestNewGame(t *testing.T) {
+	g := NewGame()
+	assert.Equal(t, StatusTurn, g.Status)
+	assert.Equal(t, PlayerX, g.Turn)
+	assert.Equal(t, -1, g.Next)
+}
+
+func TestMoveSendsOpponent(t *testing.T) {
+	g := NewGame()
+
+	require.Nil(t, MakeTurn(g, at(4, 2)))
+	assert.Equal(t, 2, g.Next)
+	assert.Equal(t, PlayerO, g.Turn)
+
+	assert.NotNil(t, MakeTurn(g, at(3, 0)))
+	require.Nil(t, MakeTurn(g, at(2, 7)))
+	assert.Equal(t, 7, g.Next)
+}
+
+func TestLegalMoves(t *testing.T) {
+	g := NewGame()
+	assert.Len(t, g.LegalMoves(), 81)
+
+	require.Nil(t, MakeTurn(g, at(4, 2)))
+	moves := g.LegalMoves()
+	assert.Len(t, moves, 9)
+	assert.Equal(t, at(2, 0), moves[0])
+}
+
+func TestOccupiedCell(t *testing.T) {
+	g := NewGame()
+
+	require.Nil(t, MakeTurn(g, at(4, 4)))
+	assert.NotNil(t, MakeTurn(g, at(4, 4)))
+	assert.NotNil(t, MakeTurn(g, Position{X: 9, Y: 0}))
+}
+
+func TestSubBoardWinFreesNextMove(t *testing.T) {
+	g := NewGame()
+
+	// X keeps sending O back to the center, where O takes the top row
+	require.Nil(t, MakeTurn(g, at(4, 4)))
+	require.Nil(t, MakeTurn(g, at(4, 0)))
+	require.Nil(t, MakeTurn(g, at(0, 4)))
+	require.Nil(t, MakeTurn(g, at(4, 1)))
+	require.Nil(t, MakeTurn(g, at(1, 4)))
+	require.Nil(t, MakeTurn(g, at(4, 2)))
+	assert.Equal(t, CellO, g.Meta[4])
+	assert.Equal(t, 2, g.Next)
+
+	// X sends O to the decided center board, so O may choose freely
+	require.Nil(t, MakeTurn(g, at(2, 4)))
+	assert.Equal(t, -1, g.Next)
+	assert.True(t, g.Playable(8))
+	assert.False(t, g.Playable(4))
+	assert.NotNil(t, MakeTurn(g, at(4, 5)))
+	require.Nil(t, MakeTurn(g, at(8, 3)))
+	assert.Equal(t, 3, g.Next)
+}
+
+func TestMetaBoardWin(t *testing.T) {
+	g := NewGame()
+	g.Meta = [9]Cell{CellX, CellX, CellEmpty, CellO, CellO, CellEmpty, CellEmpty, CellEmpty, CellEmpty}
+	g.Board[2] = [9]Cell{CellX, CellX, CellEmpty}
+
+	require.Nil(t, MakeTurn(g, at(2, 2)))
+	assert.Equal(t, CellX, g.Meta[2])
+	assert.Equal(t, StatusWin, g.Status)
+	assert.Equal(t, PlayerX, g.Winner)
+}
+
+func TestDrawnSubBoardDoesNotCount(t *testing.T) {
+	g := NewGame()
+	g.Meta = [9]Cell{CellDraw, CellX, CellEmpty, CellO, CellO, CellEmpty, CellEmpty, CellEmpty, CellEmpty}
+	g.Board[2] = [9]Cell{CellX, CellX, CellEmpty}
+
+	require.Nil(t, MakeTurn(g, at(2, 2)))
+	assert.Equal(t, StatusTurn, g.Status)
+}
+
+func TestDraw(t *testing.T) {
+	g := NewGame()
+	g.Meta = [9]Cell{CellX, CellO, CellX, CellX, CellO, CellO, CellO, CellX, CellEmpty}
+	g.Board[8] = [9]Cell{CellX, CellO, CellX, CellX, CellO, CellO, CellO, CellX, CellEmpty}
+
+	require.Nil(t, MakeTurn(g, at(8, 8)))
+	assert.Equal(t, CellDraw, g.Meta[8])
+	assert.Equal(t, StatusDraw, g.Status)
+}
@@ -0,0 +1,263 @@
+// Package variants loads m,n,k-game variants written in Starlark, so new
+// rules can be tried without recompiling the server.
+//
+// A script sets the globals name, width, height and win_row, plus an
+// optional description, and may define any of these functions:
+//
+//	shape(x, y)        whether the cell belongs to the board
+//	legal(game, x, y)  whether the side to move may play the cell
+//	outcome(game, x, y) "win", "loss", "draw" or "continue" for the side
+//	                   that just played the cell, None for the standard rules
+//
+// The game argument has the fields width, height, win_row, turn ("X" or
+// "O"), stones (the number of stones on the board) and the functions
+// cell(x, y), returning "X", "O", "" or None off the board, and run(x, y),
+// the length of the longest line through the stone at (x, y).
+package variants
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"webgames/internal/mnkgame"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+const (
+	// MaxSteps bounds the work of loading a script and of every call into
+	// it, so a runaway script cannot stall the server.
+	MaxSteps = 100_000
+	// MaxSize is the largest board side a script may ask for.
+	MaxSize = 50
+	// Ext is the extension of the script files loaded from a directory.
+	Ext = ".star"
+)
+
+// Variant is a variant loaded from a script. It implements mnkgame.Variant.
+type Variant struct {
+	name        string
+	description string
+	width       int
+	height      int
+	winRow      int
+	blocked     map[mnkgame.Position]bool
+	legal       starlark.Callable
+	outcome     starlark.Callable
+}
+
+func (v *Variant) Name() string {
+	return v.name
+}
+
+func (v *Variant) Description() string {
+	return v.description
+}
+
+// LoadDir loads every script in dir, in the order of the file names.
+func LoadDir(dir string) ([]*Variant, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+Ext))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+
+	var variants []*Variant
+	for _, file := range files {
+		v, err := Load(file, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, other := range variants {
+			if other.name == v.name {
+				return nil, fmt.Errorf("%s: variant %q is defined twice", file, v.name)
+			}
+		}
+
+		variants = append(variants, v)
+	}
+
+	return variants, nil
+}
+
+// Load runs the script in filename, or in src if it is not nil, and reads
+// the variant it defines.
+func Load(filename string, src any) (*Variant, error) {
+	if src == nil {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		src = data
+	}
+
+	globals, err := starlark.ExecFile(newThread(filename), filename, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	v := &Variant{blocked: map[mnkgame.Position]bool{}}
+	if err := v.read(globals); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	// the shape is fixed, so it is evaluated once instead of on every move
+	if shape, ok := globals["shape"].(starlark.Callable); ok {
+		for y := range v.height {
+			for x := range v.width {
+				in, err := v.call(shape, starlark.MakeInt(x), starlark.MakeInt(y))
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", filename, err)
+				}
+				if !in.Truth() {
+					v.blocked[mnkgame.Position{X: x, Y: y}] = true
+				}
+			}
+		}
+	}
+
+	return v, nil
+}
+
+func (v *Variant) read(globals starlark.StringDict) error {
+	name, ok := starlark.AsString(globals["name"])
+	if !ok || name == "" {
+		return errors.New("the script has to set a name")
+	}
+	v.name = name
+	v.description, _ = starlark.AsString(globals["description"])
+
+	for _, setting := range []struct {
+		global string
+		value  *int
+		max    int
+	}{
+		{"width", &v.width, MaxSize},
+		{"height", &v.height, MaxSize},
+		{"win_row", &v.winRow, MaxSize},
+	} {
+		n, err := starlark.AsInt32(globals[setting.global])
+		if err != nil || n < 1 || n > setting.max {
+			return fmt.Errorf("%s has to be a number from 1 to %d", setting.global, setting.max)
+		}
+		*setting.value = n
+	}
+
+	v.legal, _ = globals["legal"].(starlark.Callable)
+	v.outcome, _ = globals["outcome"].(starlark.Callable)
+
+	return nil
+}
+
+// newThread returns a thread without load or print that stops after
+// MaxSteps.
+func newThread(name string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name:  name,
+		Print: func(*starlark.Thread, string) {},
+	}
+	thread.SetMaxExecutionSteps(MaxSteps)
+
+	return thread
+}
+
+func (v *Variant) call(fn starlark.Callable, args ...starlark.Value) (starlark.Value, error) {
+	return starlark.Call(newThread(v.name), fn, args, nil)
+}
+
+func (v *Variant) Setup(g *mnkgame.Game) {
+	g.Width = v.width
+	g.Height = v.height
+	g.WinRow = v.winRow
+	// every game gets its own copy, so no game can change another's shape
+	g.Blocked = maps.Clone(v.blocked)
+}
+
+func (v *Variant) Legal(g *mnkgame.Game, pos mnkgame.Position) (bool, error) {
+	if v.legal == nil {
+		return true, nil
+	}
+
+	ok, err := v.call(v.legal, gameValue(g), starlark.MakeInt(pos.X), starlark.MakeInt(pos.Y))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", v.name, err)
+	}
+
+	return bool(ok.Truth()), nil
+}
+
+var outcomes = map[string]mnkgame.Outcome{
+	"win":      mnkgame.OutcomeWin,
+	"loss":     mnkgame.OutcomeLoss,
+	"draw":     mnkgame.OutcomeDraw,
+	"continue": mnkgame.OutcomeContinue,
+}
+
+func (v *Variant) Outcome(g *mnkgame.Game, pos mnkgame.Position) (mnkgame.Outcome, error) {
+	if v.outcome == nil {
+		return mnkgame.OutcomeStandard, nil
+	}
+
+	result, err := v.call(v.outcome, gameValue(g), starlark.MakeInt(pos.X), starlark.MakeInt(pos.Y))
+	if err != nil {
+		return mnkgame.OutcomeStandard, fmt.Errorf("%s: %w", v.name, err)
+	}
+
+	if result == starlark.None {
+		return mnkgame.OutcomeStandard, nil
+	}
+
+	s, _ := starlark.AsString(result)
+	outcome, ok := outcomes[s]
+	if !ok {
+		return mnkgame.OutcomeStandard, fmt.Errorf("%s: outcome returned %s", v.name, result)
+	}
+
+	return outcome, nil
+}
+
+// gameValue exposes the game to a script. The script only sees copies of
+// numbers and strings, so it cannot change the game.
+func gameValue(g *mnkgame.Game) starlark.Value {
+	cell := starlark.NewBuiltin("cell", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var x, y int
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 2, &x, &y); err != nil {
+			return nil, err
+		}
+
+		pos := mnkgame.Position{X: x, Y: y}
+		if !g.InBounds(pos) {
+			return starlark.None, nil
+		}
+
+		if c := g.Board[pos]; c != mnkgame.CellEmpty {
+			return starlark.String(c.String()), nil
+		}
+		return starlark.String(""), nil
+	})
+
+	run := starlark.NewBuiltin("run", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var x, y int
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 2, &x, &y); err != nil {
+			return nil, err
+		}
+
+		return starlark.MakeInt(g.LongestRun(mnkgame.Position{X: x, Y: y})), nil
+	})
+
+	return starlarkstruct.FromStringDict(starlark.String("game"), starlark.StringDict{
+		"width":   starlark.MakeInt(g.Width),
+		"height":  starlark.MakeInt(g.Height),
+		"win_row": starlark.MakeInt(g.WinRow),
+		"turn":    starlark.String(g.Turn.String()),
+		"stones":  starlark.MakeInt(len(g.Board)),
+		"cell":    cell,
+		"run":     run,
+	})
+}
@@ -0,0 +1,140 @@
+package variants
+
+import (
+	"testing"
+
+	"webgames/internal/mnkgame"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGame registers v and starts a game of it with both seats taken.
+func newGame(t *testing.T, v *Variant) *mnkgame.Game {
+	t.Helper()
+
+	if _, ok := mnkgame.FindVariant(v.Name()); !ok {
+		mnkgame.RegisterVariant(v)
+	}
+
+	g := mnkgame.CreateGame(mnkgame.CreateGameParams{PlayerID: "x", Variant: v.Name()})
+	require.Nil(t, mnkgame.BecomeOpponent(g, "o"))
+
+	return g
+}
+
+func TestLoadDir(t *testing.T) {
+	variants, err := LoadDir("../../variants")
+	require.Nil(t, err)
+
+	var names []string
+	for _, v := range variants {
+		names = append(names, v.Name())
+	}
+	assert.Equal(t, []string{"Connect Four", "Cross", "Three Loses"}, names)
+}
+
+func TestShape(t *testing.T) {
+	v, err := Load("../../variants/cross.star", nil)
+	require.Nil(t, err)
+
+	g := newGame(t, v)
+	assert.Equal(t, 7, g.Width)
+	assert.Equal(t, 4, g.WinRow)
+	assert.False(t, g.InBounds(mnkgame.Position{X: 0, Y: 0}))
+	assert.True(t, g.InBounds(mnkgame.Position{X: 3, Y: 0}))
+	assert.ErrorIs(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 6, Y: 6}), mnkgame.ErrOutOfBounds)
+	assert.Len(t, mnkgame.LegalMoves(g), 49-16)
+}
+
+func TestGamesGetTheirOwnShape(t *testing.T) {
+	v, err := Load("../../variants/cross.star", nil)
+	require.Nil(t, err)
+
+	g := newGame(t, v)
+	other := newGame(t, v)
+	g.Blocked[mnkgame.Position{X: 3, Y: 3}] = true
+	assert.True(t, other.InBounds(mnkgame.Position{X: 3, Y: 3}))
+}
+
+func TestLegal(t *testing.T) {
+	v, err := Load("../../variants/connect-four.star", nil)
+	require.Nil(t, err)
+
+	g := newGame(t, v)
+	assert.NotNil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 3, Y: 0}))
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 3, Y: 5}))
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 3, Y: 4}))
+	assert.Len(t, mnkgame.LegalMoves(g), 7)
+}
+
+func TestOutcome(t *testing.T) {
+	v, err := Load("../../variants/three-loses.star", nil)
+	require.Nil(t, err)
+
+	g := newGame(t, v)
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 0, Y: 0}))
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 0, Y: 5}))
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 1, Y: 0}))
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 1, Y: 5}))
+	require.Nil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 2, Y: 0}))
+
+	assert.Equal(t, mnkgame.StatusLoss, g.Status)
+	assert.Equal(t, mnkgame.PlayerX, g.Loser)
+	assert.Equal(t, mnkgame.PlayerO, g.Winner)
+}
+
+func TestStepLimit(t *testing.T) {
+	v, err := Load("spin.star", `
+name = "Spin"
+width = 3
+height = 3
+win_row = 3
+
+def legal(game, x, y):
+    for i in range(1000000):
+        pass
+    return True
+`)
+	require.Nil(t, err)
+
+	g := newGame(t, v)
+	assert.ErrorContains(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 1, Y: 1}), "too many steps")
+	assert.Empty(t, g.Board)
+	// a failing rule is reported, not taken for a board without moves
+	assert.Len(t, mnkgame.LegalMoves(g), 9)
+	assert.Equal(t, mnkgame.StatusTurn, g.Status)
+
+	_, err = Load("spin.star", "name = 'Spin'\nfor i in range(1000000):\n    pass\n")
+	assert.NotNil(t, err)
+}
+
+func TestFailingOutcomeTakesMoveBack(t *testing.T) {
+	v, err := Load("broken.star", `
+name = "Broken"
+width = 3
+height = 3
+win_row = 3
+
+def outcome(game, x, y):
+    return "maybe"
+`)
+	require.Nil(t, err)
+
+	g := newGame(t, v)
+	assert.NotNil(t, mnkgame.MakeTurn(g, mnkgame.Position{X: 1, Y: 1}))
+	assert.Empty(t, g.Board)
+	assert.Empty(t, g.History)
+	assert.Equal(t, mnkgame.PlayerX, g.Turn)
+}
+
+func TestInvalidScripts(t *testing.T) {
+	for name, src := range map[string]string{
+		"no name":  "width = 3\nheight = 3\nwin_row = 3\n",
+		"too wide": "name = 'Wide'\nwidth = 51\nheight = 3\nwin_row = 3\n",
+		"load":     "load('other.star', 'x')\nname = 'Load'\n",
+	} {
+		_, err := Load(name+".star", src)
+		assert.NotNil(t, err, name)
+	}
+}
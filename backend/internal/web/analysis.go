@@ -0,0 +1,119 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"webgames/internal/analysis"
+	"webgames/internal/hints"
+	"webgames/internal/mnkgame"
+	"webgames/internal/pubsub"
+	"webgames/internal/threats"
+
+	datastar "github.com/starfederation/datastar/sdk/go"
+)
+
+// showForcedWin searches the position of an analysis board for a forced win
+// of the side to move.
+func showForcedWin() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			game, ok := mnkgame.FindGame(mnkgame.GameID(r.PathValue("gameID")))
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			// in a game against others this would play for the player
+			if !isAnalysis(game, mnkgame.PlayerID(getUserID(r.Context()))) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			win, found := threats.FindVCT(game)
+
+			sse := datastar.NewSSE(w, r)
+			sse.MergeFragmentTempl(ForcedWin(game, win, found))
+		},
+	)
+}
+
+// analysisSSE streams the analysis of a finished game, starting it on the
+// first request, until every turn is analysed.
+func analysisSSE(ps *pubsub.PubSub[struct{}]) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			game, ok := mnkgame.FindGame(mnkgame.GameID(r.PathValue("gameID")))
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			if !(mnkGame{game}).Over() {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+
+			// every game has its own topic, so watchers are not woken by
+			// the analyses of other games
+			topic := "analysis/" + string(game.ID)
+			ch := ps.Subscribe(topic)
+			defer ps.Unsubscribe(topic, ch)
+
+			report, release := analysis.Start(game, func() {
+				ps.Publish(topic, struct{}{})
+			})
+			defer release()
+
+			sse := datastar.NewSSE(w, r)
+			for {
+				evals, done := report.Evals()
+				sse.MergeFragmentTempl(GameAnalysis(game, evals, done))
+				if done {
+					return
+				}
+
+				select {
+				case <-r.Context().Done():
+					slog.Debug("Client connection closed")
+					return
+				case <-ch:
+				}
+			}
+		},
+	)
+}
+
+// suggestMove asks the engine for a move in a game with hints. Only the
+// player to move gets the suggestion.
+func suggestMove() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			game, ok := mnkgame.FindGame(mnkgame.GameID(r.PathValue("gameID")))
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			if !isActive(game, mnkgame.PlayerID(getUserID(r.Context()))) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			move, err := hints.Suggest(game)
+			if errors.Is(err, hints.ErrDisabled) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				log.Println(err)
+				return
+			}
+
+			sse := datastar.NewSSE(w, r)
+			sse.MergeFragmentTempl(HintSuggestion(game, move))
+		},
+	)
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"webgames/internal/dotsboxes"
+	"webgames/internal/games"
+
+	"github.com/a-h/templ"
+)
+
+func init() {
+	games.Register(dotsBoxesType{})
+}
+
+// dotsBoxesForm holds the signals of the Dots and Boxes section of the main
+// page, named apart from the ones of the m,n,k form posted alongside them.
+type dotsBoxesForm struct {
+	DotsWidth  int
+	DotsHeight int
+}
+
+type dotsBoxesType struct{}
+
+func (dotsBoxesType) Name() string {
+	return "dots-and-boxes"
+}
+
+func (dotsBoxesType) Create(playerID string, params []byte) (games.Game, error) {
+	var form dotsBoxesForm
+	if err := json.Unmarshal(params, &form); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	game := dotsboxes.CreateGame(dotsboxes.CreateGameParams{
+		PlayerID: playerID,
+		Width:    form.DotsWidth,
+		Height:   form.DotsHeight,
+	})
+
+	return dotsBoxesGame{game}, nil
+}
+
+func (dotsBoxesType) Find(id string) (games.Game, bool) {
+	game, ok := dotsboxes.FindGame(dotsboxes.GameID(id))
+	return dotsBoxesGame{game}, ok
+}
+
+type dotsBoxesGame struct {
+	game *dotsboxes.Game
+}
+
+func (g dotsBoxesGame) ID() string {
+	return string(g.game.ID)
+}
+
+func (g dotsBoxesGame) Status() string {
+	return g.game.StatusText()
+}
+
+func (g dotsBoxesGame) Over() bool {
+	return g.game.Status == dotsboxes.StatusWin || g.game.Status == dotsboxes.StatusDraw
+}
+
+func (g dotsBoxesGame) Join(playerID string) error {
+	return dotsboxes.BecomeOpponent(g.game, dotsboxes.PlayerID(playerID))
+}
+
+func (g dotsBoxesGame) Active(playerID string) bool {
+	return isDotsBoxesActive(g.game, dotsboxes.PlayerID(playerID))
+}
+
+func (g dotsBoxesGame) DecodeMove(data []byte) (games.Move, error) {
+	var params dotsboxes.MakeTurnParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	return params.Edge(), nil
+}
+
+func (g dotsBoxesGame) MakeTurn(move games.Move) error {
+	edge, err := moveAs[dotsboxes.Edge](move)
+	if err != nil {
+		return err
+	}
+
+	return dotsboxes.MakeTurn(g.game, edge)
+}
+
+func (g dotsBoxesGame) LegalMoves(playerID string) []games.Move {
+	if !g.Active(playerID) {
+		return nil
+	}
+
+	return anyMoves(g.game.LegalMoves())
+}
+
+func (g dotsBoxesGame) Page(playerID string) templ.Component {
+	return DotsBoxesPage(g.game, dotsboxes.PlayerID(playerID))
+}
+
+func (g dotsBoxesGame) Board(playerID string) templ.Component {
+	return DotsBoxesBoard(g.game, dotsboxes.PlayerID(playerID))
+}
+
+func (g dotsBoxesGame) Serialize(playerID string) ([]byte, error) {
+	return json.Marshal(g.game)
+}
@@ -0,0 +1,152 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"webgames/internal/engine"
+	"webgames/internal/mnkgame"
+	"webgames/internal/notation"
+
+	datastar "github.com/starfederation/datastar/sdk/go"
+)
+
+// editorSignals are the signals of the position editor page.
+type editorSignals struct {
+	Position     string
+	Brush        mnkgame.Cell
+	X            int
+	Y            int
+	Z            int
+	EditorWidth  int
+	EditorHeight int
+	EditorWinRow int
+	EditorBot    bool
+}
+
+func getEditor() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			game := mnkgame.NewGame(15, 15, 5)
+			editorError := ""
+			if position := r.URL.Query().Get("position"); position != "" {
+				// a broken link opens the default board and says why
+				parsed, err := notation.ParsePosition(position)
+				if err != nil {
+					editorError = err.Error()
+				} else {
+					game = parsed
+				}
+			}
+
+			EditorPage(game, editorError).Render(r.Context(), w)
+		},
+	)
+}
+
+// editPosition applies an editor action to the position in the signals and
+// sends back the new position, or starts a game from it.
+func editPosition() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			signals, err := readJSON[editorSignals](r)
+			if err != nil {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				log.Println(err)
+				return
+			}
+
+			if signals.Brush < mnkgame.CellEmpty || signals.Brush > mnkgame.CellSquare {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+
+			sse := datastar.NewSSE(w, r)
+
+			game, err := notation.ParsePosition(signals.Position)
+			if r.PathValue("action") == "new" {
+				game, err = newEditorBoard(signals)
+			}
+			if err != nil {
+				mergeEditorError(sse, err)
+				return
+			}
+
+			switch r.PathValue("action") {
+			case "place":
+				pos := mnkgame.Position{X: signals.X, Y: signals.Y, Z: signals.Z}
+				if !game.InBounds(pos) {
+					break
+				}
+				if signals.Brush == mnkgame.CellEmpty {
+					delete(game.Board, pos)
+				} else {
+					game.Board[pos] = signals.Brush
+				}
+			case "turn":
+				if signals.Brush != mnkgame.CellEmpty {
+					game.Turn = mnkgame.Player(signals.Brush)
+				}
+			case "play", "analyse":
+				if err := notation.CheckPlayable(game); err != nil {
+					mergeEditorError(sse, err)
+					return
+				}
+
+				playerID := mnkgame.PlayerID(getUserID(r.Context()))
+				if r.PathValue("action") == "play" {
+					game = mnkgame.HostGame(game, playerID, signals.EditorBot)
+					if err := engine.Play(game); err != nil {
+						log.Println(err)
+					}
+				} else {
+					game = mnkgame.ImportGame(game, playerID)
+				}
+
+				sse.Redirect(fmt.Sprintf("/games/%s", game.ID))
+				return
+			}
+
+			// the position is parsed again, so the side count follows the marks
+			position, err := notation.FormatPosition(game)
+			if err == nil {
+				game, err = notation.ParsePosition(position)
+			}
+			if err != nil {
+				mergeEditorError(sse, err)
+				return
+			}
+
+			mergeEditorSignals(sse, map[string]any{"position": position, "editorError": ""})
+			sse.MergeFragmentTempl(EditorBoard(game))
+		},
+	)
+}
+
+func newEditorBoard(signals editorSignals) (*mnkgame.Game, error) {
+	size := notation.MaxPositionSize
+	if signals.EditorWidth < 1 || signals.EditorWidth > size || signals.EditorHeight < 1 || signals.EditorHeight > size {
+		return nil, fmt.Errorf("the board has to be between 1x1 and %dx%d", size, size)
+	}
+
+	if signals.EditorWinRow < 1 || signals.EditorWinRow > max(signals.EditorWidth, signals.EditorHeight) {
+		return nil, fmt.Errorf("the win row has to fit on the board")
+	}
+
+	return mnkgame.NewGame(signals.EditorWidth, signals.EditorHeight, signals.EditorWinRow), nil
+}
+
+func mergeEditorError(sse *datastar.ServerSentEventGenerator, err error) {
+	mergeEditorSignals(sse, map[string]any{"editorError": err.Error()})
+}
+
+func mergeEditorSignals(sse *datastar.ServerSentEventGenerator, signals map[string]any) {
+	data, err := json.Marshal(signals)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	sse.MergeSignals(data)
+}
@@ -0,0 +1,129 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"webgames/internal/book"
+	"webgames/internal/engine"
+	"webgames/internal/games"
+	"webgames/internal/mnkgame"
+
+	"github.com/a-h/templ"
+)
+
+func init() {
+	games.Register(mnkType{})
+}
+
+type mnkType struct{}
+
+// Name keeps the /games/ prefix the m,n,k games were first served under.
+func (mnkType) Name() string {
+	return "games"
+}
+
+func (mnkType) Create(playerID string, params []byte) (games.Game, error) {
+	var p mnkgame.CreateGameParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+
+	p.PlayerID = playerID
+	game := mnkgame.CreateGame(p)
+
+	log.Println(*game)
+
+	return mnkGame{game}, nil
+}
+
+func (mnkType) Find(id string) (games.Game, bool) {
+	game, ok := mnkgame.FindGame(mnkgame.GameID(id))
+	return mnkGame{game}, ok
+}
+
+type mnkGame struct {
+	game *mnkgame.Game
+}
+
+func (g mnkGame) ID() string {
+	return string(g.game.ID)
+}
+
+func (g mnkGame) Status() string {
+	return g.game.StatusText()
+}
+
+func (g mnkGame) Over() bool {
+	return g.game.Status != mnkgame.StatusOpponent && g.game.Status != mnkgame.StatusTurn
+}
+
+func (g mnkGame) Join(playerID string) error {
+	return mnkgame.BecomeOpponent(g.game, mnkgame.PlayerID(playerID))
+}
+
+func (g mnkGame) Active(playerID string) bool {
+	return isActive(g.game, mnkgame.PlayerID(playerID))
+}
+
+func (g mnkGame) DecodeMove(data []byte) (games.Move, error) {
+	return decodeMove[mnkgame.MakeTurnParams](data)
+}
+
+// MakeTurn plays the move and then the replies of the computer seats.
+func (g mnkGame) MakeTurn(move games.Move) error {
+	params, err := moveAs[mnkgame.MakeTurnParams](move)
+	if err != nil {
+		return err
+	}
+
+	err = mnkgame.MakeMarkedTurn(g.game, params.Position(), params.Mark)
+	if errors.Is(err, mnkgame.ErrProbed) {
+		// the player found a hidden stone and keeps the turn
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := engine.Play(g.game); err != nil {
+		log.Println(err)
+	}
+
+	// analysis boards explore lines rather than play them, so they stay out
+	// of the opening book
+	if g.Over() && book.Check(g.game) == nil && !isAnalysis(g.game, g.game.PlayerID(mnkgame.PlayerX)) {
+		if err := book.Default.Add(g.game); err != nil {
+			log.Println(err)
+		}
+	}
+
+	return nil
+}
+
+func (g mnkGame) LegalMoves(playerID string) []games.Move {
+	if !g.Active(playerID) {
+		return nil
+	}
+
+	return anyMoves(mnkgame.LegalMoves(g.game.View(mnkgame.PlayerID(playerID))))
+}
+
+func (g mnkGame) Page(playerID string) templ.Component {
+	id := mnkgame.PlayerID(playerID)
+	return GamePage(g.game.View(id), id)
+}
+
+func (g mnkGame) Board(playerID string) templ.Component {
+	id := mnkgame.PlayerID(playerID)
+	return GameBoard(g.game.View(id), id)
+}
+
+func (g mnkGame) Serialize(playerID string) ([]byte, error) {
+	return json.Marshal(g.game.View(mnkgame.PlayerID(playerID)))
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"webgames/internal/mnkgame"
+	"webgames/internal/notation"
+)
+
+// maxRecordSize bounds uploaded game records.
+const maxRecordSize = 1 << 20
+
+// downloadRecord serves an m,n,k game as a text record, or as a PSQ record
+// with ?format=psq.
+func downloadRecord() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			gameID := mnkgame.GameID(r.PathValue("gameID"))
+			game, ok := mnkgame.FindGame(gameID)
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			// a record would give away the stones a Phantom player cannot see
+			if game.View(mnkgame.PlayerID(getUserID(r.Context()))) != game {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			var buf bytes.Buffer
+			write, ext := notation.WriteText, "txt"
+			if r.URL.Query().Get("format") == "psq" {
+				write, ext = notation.WritePSQ, "psq"
+			}
+
+			if err := write(&buf, game); err != nil {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				log.Println(err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="game-%s.%s"`, game.ID, ext))
+			w.Write(buf.Bytes())
+		},
+	)
+}
+
+// uploadRecord starts a game from an uploaded text or PSQ record, telling
+// the formats apart by the PSQ header.
+func uploadRecord() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxRecordSize)
+			file, _, err := r.FormFile("record")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				log.Println(err)
+				return
+			}
+			defer file.Close()
+
+			record := bufio.NewReader(file)
+			header, _ := record.Peek(len("Piskvorky"))
+
+			var game *mnkgame.Game
+			if string(header) == "Piskvorky" {
+				game, err = notation.ReadPSQ(record, mnkgame.RulesStandard)
+			} else {
+				game, err = notation.ReadText(record)
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprintln(w, err)
+				return
+			}
+
+			game = mnkgame.ImportGame(game, mnkgame.PlayerID(getUserID(r.Context())))
+			http.Redirect(w, r, fmt.Sprintf("/games/%s", game.ID), http.StatusSeeOther)
+		},
+	)
+}
@@ -0,0 +1,89 @@
+package web
+
+import (
+	"encoding/json"
+	"webgames/internal/games"
+	"webgames/internal/reversi"
+
+	"github.com/a-h/templ"
+)
+
+func init() {
+	games.Register(reversiType{})
+}
+
+type reversiType struct{}
+
+func (reversiType) Name() string {
+	return "reversi"
+}
+
+func (reversiType) Create(playerID string, params []byte) (games.Game, error) {
+	game := reversi.CreateGame(reversi.CreateGameParams{
+		PlayerID: playerID,
+	})
+
+	return reversiGame{game}, nil
+}
+
+func (reversiType) Find(id string) (games.Game, bool) {
+	game, ok := reversi.FindGame(reversi.GameID(id))
+	return reversiGame{game}, ok
+}
+
+type reversiGame struct {
+	game *reversi.Game
+}
+
+func (g reversiGame) ID() string {
+	return string(g.game.ID)
+}
+
+func (g reversiGame) Status() string {
+	return g.game.StatusText()
+}
+
+func (g reversiGame) Over() bool {
+	return g.game.Status == reversi.StatusWin || g.game.Status == reversi.StatusDraw
+}
+
+func (g reversiGame) Join(playerID string) error {
+	return reversi.BecomeOpponent(g.game, reversi.PlayerID(playerID))
+}
+
+func (g reversiGame) Active(playerID string) bool {
+	return isReversiActive(g.game, reversi.PlayerID(playerID))
+}
+
+func (g reversiGame) DecodeMove(data []byte) (games.Move, error) {
+	return decodeMove[reversi.Position](data)
+}
+
+func (g reversiGame) MakeTurn(move games.Move) error {
+	pos, err := moveAs[reversi.Position](move)
+	if err != nil {
+		return err
+	}
+
+	return reversi.MakeTurn(g.game, pos)
+}
+
+func (g reversiGame) LegalMoves(playerID string) []games.Move {
+	if !g.Active(playerID) {
+		return nil
+	}
+
+	return anyMoves(g.game.LegalMoves(g.game.Turn))
+}
+
+func (g reversiGame) Page(playerID string) templ.Component {
+	return ReversiPage(g.game, reversi.PlayerID(playerID))
+}
+
+func (g reversiGame) Board(playerID string) templ.Component {
+	return ReversiBoard(g.game, reversi.PlayerID(playerID))
+}
+
+func (g reversiGame) Serialize(playerID string) ([]byte, error) {
+	return json.Marshal(g.game)
+}
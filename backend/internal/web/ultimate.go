@@ -0,0 +1,89 @@
+package web
+
+import (
+	"encoding/json"
+	"webgames/internal/games"
+	"webgames/internal/ultimate"
+
+	"github.com/a-h/templ"
+)
+
+func init() {
+	games.Register(ultimateType{})
+}
+
+type ultimateType struct{}
+
+func (ultimateType) Name() string {
+	return "ultimate"
+}
+
+func (ultimateType) Create(playerID string, params []byte) (games.Game, error) {
+	game := ultimate.CreateGame(ultimate.CreateGameParams{
+		PlayerID: playerID,
+	})
+
+	return ultimateGame{game}, nil
+}
+
+func (ultimateType) Find(id string) (games.Game, bool) {
+	game, ok := ultimate.FindGame(ultimate.GameID(id))
+	return ultimateGame{game}, ok
+}
+
+type ultimateGame struct {
+	game *ultimate.Game
+}
+
+func (g ultimateGame) ID() string {
+	return string(g.game.ID)
+}
+
+func (g ultimateGame) Status() string {
+	return g.game.StatusText()
+}
+
+func (g ultimateGame) Over() bool {
+	return g.game.Status == ultimate.StatusWin || g.game.Status == ultimate.StatusDraw
+}
+
+func (g ultimateGame) Join(playerID string) error {
+	return ultimate.BecomeOpponent(g.game, ultimate.PlayerID(playerID))
+}
+
+func (g ultimateGame) Active(playerID string) bool {
+	return isUltimateActive(g.game, ultimate.PlayerID(playerID))
+}
+
+func (g ultimateGame) DecodeMove(data []byte) (games.Move, error) {
+	return decodeMove[ultimate.Position](data)
+}
+
+func (g ultimateGame) MakeTurn(move games.Move) error {
+	pos, err := moveAs[ultimate.Position](move)
+	if err != nil {
+		return err
+	}
+
+	return ultimate.MakeTurn(g.game, pos)
+}
+
+func (g ultimateGame) LegalMoves(playerID string) []games.Move {
+	if !g.Active(playerID) {
+		return nil
+	}
+
+	return anyMoves(g.game.LegalMoves())
+}
+
+func (g ultimateGame) Page(playerID string) templ.Component {
+	return UltimatePage(g.game, ultimate.PlayerID(playerID))
+}
+
+func (g ultimateGame) Board(playerID string) templ.Component {
+	return UltimateBoard(g.game, ultimate.PlayerID(playerID))
+}
+
+func (g ultimateGame) Serialize(playerID string) ([]byte, error) {
+	return json.Marshal(g.game)
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"net/http"
 	"time"
-	"webgames/internal/mnkgame"
+	"webgames/internal/games"
 	"webgames/internal/pubsub"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -42,11 +43,22 @@ func ListenAndServe(addr string) error {
 	})
 
 	mux.Handle("GET /assets/", http.StripPrefix("/assets/", assetHandler))
-	mux.Handle("GET /games/{gameID}/sse", md(sseHandler(ps)))
-	mux.Handle("GET /games/{gameID}", md(getGame()))
-	mux.Handle("POST /games/{gameID}/turn", md(makeTurn(ps)))
-	mux.Handle("POST /games/{gameID}/opponent", md(becomeOpponent(ps)))
-	mux.Handle("POST /games", md(createGame()))
+	for _, t := range games.Types() {
+		prefix := "/" + t.Name()
+		mux.Handle("GET "+prefix+"/{gameID}/sse", md(sseHandler(ps, t)))
+		mux.Handle("GET "+prefix+"/{gameID}/state", md(getState(t)))
+		mux.Handle("GET "+prefix+"/{gameID}", md(getGame(t)))
+		mux.Handle("POST "+prefix+"/{gameID}/turn", md(makeTurn(ps, t)))
+		mux.Handle("POST "+prefix+"/{gameID}/opponent", md(becomeOpponent(ps, t)))
+		mux.Handle("POST "+prefix, md(createGame(t)))
+	}
+	mux.Handle("GET /games/{gameID}/record", md(downloadRecord()))
+	mux.Handle("POST /games/import", md(uploadRecord()))
+	mux.Handle("POST /games/{gameID}/forced-win", md(showForcedWin()))
+	mux.Handle("GET /games/{gameID}/analysis", md(analysisSSE(ps)))
+	mux.Handle("POST /games/{gameID}/hint", md(suggestMove()))
+	mux.Handle("GET /editor", md(getEditor()))
+	mux.Handle("POST /editor/{action}", md(editPosition()))
 	mux.Handle("GET /", md(mainHandler()))
 
 	server := &http.Server{
@@ -73,18 +85,55 @@ func mainHandler() http.Handler {
 	)
 }
 
-func getGame() http.Handler {
+func getGame(t games.Type) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
-			gameID := mnkgame.GameID(r.PathValue("gameID"))
-			game, ok := mnkgame.FindGame(gameID)
+			game, ok := t.Find(r.PathValue("gameID"))
 			if !ok {
 				w.WriteHeader(http.StatusNotFound)
 				return
 			}
-			playerID := r.Context().Value(contextKeyUserID).(string)
+			playerID := getUserID(r.Context())
 
-			GamePage(game, mnkgame.PlayerID(playerID)).Render(r.Context(), w)
+			game.Page(playerID).Render(r.Context(), w)
+		},
+	)
+}
+
+// gameState is the JSON form of a game served to API clients.
+type gameState struct {
+	Status     string
+	Over       bool
+	LegalMoves []games.Move
+	Game       json.RawMessage
+}
+
+func getState(t games.Type) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			game, ok := t.Find(r.PathValue("gameID"))
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			playerID := getUserID(r.Context())
+
+			data, err := game.Serialize(playerID)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				log.Println(err)
+				return
+			}
+
+			state := gameState{
+				Status:     game.Status(),
+				Over:       game.Over(),
+				LegalMoves: game.LegalMoves(playerID),
+				Game:       data,
+			}
+			if err := writeJSON(w, http.StatusOK, state); err != nil {
+				log.Println(err)
+			}
 		},
 	)
 }
@@ -93,96 +142,98 @@ func getUserID(ctx context.Context) string {
 	return ctx.Value(contextKeyUserID).(string)
 }
 
-func createGame() http.Handler {
+func createGame(t games.Type) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
-			params, err := readJSON[mnkgame.CreateGameParams](r)
+			params, err := io.ReadAll(r.Body)
 			if err != nil {
 				w.WriteHeader(http.StatusUnprocessableEntity)
 				log.Println(err)
 				return
 			}
 
-			params.PlayerXID = getUserID(r.Context())
-			game := mnkgame.CreateGame(params)
-
-			log.Println(*game)
+			game, err := t.Create(getUserID(r.Context()), params)
+			if err != nil {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				log.Println(err)
+				return
+			}
 
 			sse := datastar.NewSSE(w, r)
-			url := fmt.Sprintf("/games/%s", game.ID)
+			url := fmt.Sprintf("/%s/%s", t.Name(), game.ID())
 			sse.Redirect(url)
 		},
 	)
 }
 
-func makeTurn(ps *pubsub.PubSub[struct{}]) http.Handler {
+func makeTurn(ps *pubsub.PubSub[struct{}], t games.Type) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
-			gameID := mnkgame.GameID(r.PathValue("gameID"))
-			game, ok := mnkgame.FindGame(gameID)
+			game, ok := t.Find(r.PathValue("gameID"))
 			if !ok {
 				w.WriteHeader(http.StatusNotFound)
 				return
 			}
 
-			position, err := readJSON[mnkgame.Position](r)
+			if !game.Active(getUserID(r.Context())) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			data, err := io.ReadAll(r.Body)
 			if err != nil {
 				w.WriteHeader(http.StatusUnprocessableEntity)
 				return
 			}
 
-			err = mnkgame.MakeTurn(game, position)
+			move, err := game.DecodeMove(data)
+			if err != nil {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
 
+			err = game.MakeTurn(move)
 			if err != nil {
 				w.WriteHeader(http.StatusUnprocessableEntity)
 				log.Println(err)
 				return
 			}
 
-			// sse := datastar.NewSSE(w, r)
-			// sse.MergeFragmentTempl(Cell(game.Board[position.Y][position.X].String(), position.X, position.Y))
-
 			ps.Publish("game", struct{}{})
 		},
 	)
 }
 
-func becomeOpponent(ps *pubsub.PubSub[struct{}]) http.Handler {
+func becomeOpponent(ps *pubsub.PubSub[struct{}], t games.Type) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
-			gameID := mnkgame.GameID(r.PathValue("gameID"))
-			game, ok := mnkgame.FindGame(gameID)
+			game, ok := t.Find(r.PathValue("gameID"))
 			if !ok {
 				w.WriteHeader(http.StatusNotFound)
 				return
 			}
 
-			playerID := r.Context().Value(contextKeyUserID).(string)
-			err := mnkgame.BecomeOpponent(game, mnkgame.PlayerID(playerID))
+			err := game.Join(getUserID(r.Context()))
 			if err != nil {
 				w.WriteHeader(http.StatusBadRequest)
 				return
 			}
 
 			ps.Publish("game", struct{}{})
-
-			// sse := datastar.NewSSE(w, r)
-			// sse.MergeFragmentTempl(GameBoard(game, mnkgame.PlayerID(playerID)))
 		},
 	)
 }
 
-func sseHandler(ps *pubsub.PubSub[struct{}]) http.Handler {
+func sseHandler(ps *pubsub.PubSub[struct{}], t games.Type) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
-			gameID := mnkgame.GameID(r.PathValue("gameID"))
-			_, ok := mnkgame.FindGame(gameID)
+			game, ok := t.Find(r.PathValue("gameID"))
 			if !ok {
 				w.WriteHeader(http.StatusNotFound)
 				return
 			}
 
-			playerID := r.Context().Value(contextKeyUserID).(string)
+			playerID := getUserID(r.Context())
 			ch := ps.Subscribe("game")
 			defer ps.Unsubscribe("game", ch)
 
@@ -193,8 +244,7 @@ func sseHandler(ps *pubsub.PubSub[struct{}]) http.Handler {
 					slog.Debug("Client connection closed")
 					return
 				case <-ch:
-					game, _ := mnkgame.FindGame(gameID)
-					sse.MergeFragmentTempl(GameBoard(game, mnkgame.PlayerID(playerID)))
+					sse.MergeFragmentTempl(game.Board(playerID))
 				}
 			}
 
@@ -202,6 +252,31 @@ func sseHandler(ps *pubsub.PubSub[struct{}]) http.Handler {
 	)
 }
 
+// decodeMove reads a move sent as the JSON form of T.
+func decodeMove[T any](data []byte) (games.Move, error) {
+	var move T
+	if err := json.Unmarshal(data, &move); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	return move, nil
+}
+
+func moveAs[T any](move games.Move) (T, error) {
+	m, ok := move.(T)
+	if !ok {
+		return m, fmt.Errorf("unexpected move %v of type %T", move, move)
+	}
+	return m, nil
+}
+
+func anyMoves[T any](moves []T) []games.Move {
+	out := make([]games.Move, len(moves))
+	for i, m := range moves {
+		out[i] = m
+	}
+	return out
+}
+
 func writeJSON[T any](w http.ResponseWriter, status int, v T) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -2,14 +2,24 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"time"
 
+	"webgames/internal/mnkgame"
+	"webgames/internal/solver"
+	"webgames/internal/variants"
 	"webgames/internal/web"
 )
 
+// tablebasesDir holds the tablebases written by the solve subcommand and
+// loaded by the server.
+const tablebasesDir = "./tablebases"
+
 func main() {
 	ctx := context.Background()
 	if err := run(ctx, os.Stdout, os.Args); err != nil {
@@ -26,9 +36,87 @@ func run(
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
 	defer cancel()
 
+	if len(args) > 1 && args[1] == "solve" {
+		return solve(ctx, stdout, args[2:])
+	}
+
+	scripted, err := variants.LoadDir("./variants")
+	if err != nil {
+		return err
+	}
+	for _, v := range scripted {
+		mnkgame.RegisterVariant(v)
+	}
+
+	tablebases, err := solver.LoadDir(tablebasesDir)
+	if err != nil {
+		return err
+	}
+	for _, s := range tablebases {
+		solver.Register(s)
+	}
+
 	if err := web.ListenAndServe(":3000"); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// solve runs the solve subcommand: it solves an empty board of the given
+// configuration and writes the tablebase into the tablebases directory.
+func solve(ctx context.Context, stdout io.Writer, args []string) error {
+	flags := flag.NewFlagSet("solve", flag.ContinueOnError)
+	width := flags.Int("width", 3, "board width")
+	height := flags.Int("height", 3, "board height")
+	winRow := flags.Int("k", 3, "stones in a row to win")
+	misere := flags.Bool("misere", false, "completing a line loses")
+	out := flags.String("o", "", "tablebase file, by default tablebases/WxH-k.tb")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	game := mnkgame.NewGame(*width, *height, *winRow)
+	name := fmt.Sprintf("%dx%d-%d", *width, *height, *winRow)
+	if *misere {
+		game.Rules = mnkgame.RulesMisere
+		name += "-misere"
+	}
+	if *out == "" {
+		*out = filepath.Join(tablebasesDir, name+".tb")
+	}
+
+	config, err := solver.ConfigOf(game)
+	if err != nil {
+		return err
+	}
+	s, err := solver.New(config)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	result, err := s.Solve(ctx, game)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s: %s for X, %d positions in %s\n",
+		config, result, s.Positions(), time.Since(start).Round(time.Millisecond))
+
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	n, err := s.WriteTo(f)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "wrote %s (%d bytes)\n", *out, n)
+
+	return f.Close()
+}